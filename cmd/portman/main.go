@@ -7,7 +7,5 @@ import (
 )
 
 func main() {
-	if err := cli.RootCmd.Execute(); err != nil {
-		os.Exit(1)
-	}
+	os.Exit(cli.Execute())
 }
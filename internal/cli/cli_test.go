@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// run executes RootCmd with args, away from the user's config files, and
+// returns what it printed to stdout
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("PORTMAN_REGISTRY", "")
+	t.Chdir(t.TempDir())
+	resetFlags(RootCmd)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&out, r)
+		close(done)
+	}()
+
+	RootCmd.SetArgs(args)
+	RootCmd.SilenceErrors = true
+	RootCmd.SilenceUsage = true
+	err = RootCmd.Execute()
+
+	w.Close()
+	<-done
+	return out.String(), err
+}
+
+// resetFlags restores every flag of cmd and its subcommands to its
+// default, as flag values outlive an Execute call
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, c := range cmd.Commands() {
+		resetFlags(c)
+	}
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/tasnimzotder/portman/internal/config"
+)
+
+// settings is the loaded configuration, nil until loadConfig runs
+var settings *config.Config
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	RootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect portman's configuration",
+	Long: `portman reads ~/.config/portman/config.yaml and then the nearest
+.portman.yaml in the working directory or its parents. Later files
+override earlier ones key by key, and flags override both. Defaults that
+kill, run commands or write files (kill.yes, kill.force, kill.signal,
+wait.exec, log-file) are only read from ~/.config/portman/config.yaml;
+a .portman.yaml that sets one gets a warning.
+
+  defaults:        # Default flag values
+    sort: user
+    interval: 2s
+    columns: [port, pid, user, rss]
+    stats: true
+    kill.yes: true # Flags of one command: <command>.<flag>
+  aliases:         # Names for port arguments
+    api: 8080
+    db: localhost:5432
+  groups:          # Named port lists, used as @stack
+    stack: [3000, api, db]`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective merged configuration",
+	Args:  checkArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Print(formatSettings(settings))
+		return nil
+	},
+}
+
+// loadConfig loads the config files and applies their defaults to the
+// flags of cmd that weren't set on the command line
+func loadConfig(cmd *cobra.Command) error {
+	c, err := config.Load()
+	if err != nil {
+		var cfgErr *config.Error
+		if errors.As(err, &cfgErr) {
+			return &Error{Code: CodeInvalidArgument, Message: "config: " + err.Error(), Err: err}
+		}
+		return err
+	}
+	settings = c
+	for _, w := range c.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: config: %s\n", w)
+	}
+
+	for _, key := range config.Keys(c.Defaults) {
+		s := c.Defaults[key]
+		flag, err := defaultFlag(cmd, key)
+		if err != nil {
+			return &Error{Code: CodeInvalidArgument, Message: fmt.Sprintf("config: %s: %s", s.Where(), err), Err: err}
+		}
+		if flag == nil || flag.Changed {
+			continue
+		}
+
+		value := s.String()
+		if key == "columns" {
+			value = "columns=" + value
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return &Error{Code: CodeInvalidArgument, Message: fmt.Sprintf("config: %s: invalid %s: %s", s.Where(), key, err), Err: err}
+		}
+	}
+	return nil
+}
+
+// defaultFlag returns the flag a defaults key sets for cmd. Plain keys name
+// global flags, with "columns" standing for -o columns=; "<command>.<flag>"
+// keys name a command's own flags and return nil for other commands.
+func defaultFlag(cmd *cobra.Command, key string) (*pflag.Flag, error) {
+	root := cmd.Root()
+	global := root.PersistentFlags()
+
+	name, flagName, scoped := strings.Cut(key, ".")
+	if !scoped {
+		if key == "columns" {
+			if global.Lookup("output").Changed || jsonOutput {
+				return nil, nil
+			}
+			return global.Lookup("output"), nil
+		}
+		if flag := global.Lookup(key); flag != nil {
+			return flag, nil
+		}
+		return nil, fmt.Errorf("unknown default %q (not a global flag; use <command>.<flag> for command flags)", key)
+	}
+
+	target, _, err := root.Find([]string{name})
+	if err != nil || target == root {
+		return nil, fmt.Errorf("unknown command %q in default %q", name, key)
+	}
+	flag := target.Flags().Lookup(flagName)
+	if flag == nil {
+		return nil, fmt.Errorf("unknown flag %q for %s in default %q", flagName, name, key)
+	}
+	if target != cmd {
+		return nil, nil
+	}
+	return flag, nil
+}
+
+// formatSettings renders the merged configuration in config file syntax,
+// noting where each value came from
+func formatSettings(c *config.Config) string {
+	var sb strings.Builder
+	if c == nil || len(c.Files) == 0 {
+		sb.WriteString(fmt.Sprintf("# No config files found (looked for %s and %s)\n", config.GlobalPath(), config.LocalName))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("# Files: %s\n", strings.Join(c.Files, ", ")))
+	sections := []struct {
+		name   string
+		values map[string]config.Setting
+		list   bool
+	}{
+		{"defaults", c.Defaults, false},
+		{"aliases", c.Aliases, false},
+		{"groups", c.Groups, true},
+	}
+	for _, sec := range sections {
+		if len(sec.values) == 0 {
+			continue
+		}
+		sb.WriteString(sec.name + ":\n")
+		for _, key := range config.Keys(sec.values) {
+			s := sec.values[key]
+			value := yamlValue(s.Value[0])
+			if sec.list || len(s.Value) > 1 {
+				items := make([]string, len(s.Value))
+				for i, v := range s.Value {
+					items[i] = yamlValue(v)
+				}
+				value = "[" + strings.Join(items, ", ") + "]"
+			}
+			sb.WriteString(fmt.Sprintf("  %s: %s  # %s\n", key, value, s.Where()))
+		}
+	}
+	return sb.String()
+}
+
+// yamlValue quotes a scalar when it would otherwise read differently
+func yamlValue(s string) string {
+	if s == "" || strings.ContainsAny(s, "#,[]'\"") || strings.HasPrefix(s, "@") || strings.TrimSpace(s) != s {
+		return strconv.Quote(s)
+	}
+	return s
+}
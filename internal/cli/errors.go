@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tasnimzotder/portman/internal/kill"
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/scanner"
+)
+
+// Error codes are stable identifiers wrappers can branch on
+const (
+	CodeError               = "error"
+	CodePermissionDenied    = "permission_denied"
+	CodeStillRunning        = "still_running"
+	CodeNotFound            = "not_found"
+	CodeTimeout             = "timeout"
+	CodeUnsupportedPlatform = "unsupported_platform"
+	CodePartialResults      = "partial_results"
+	CodeInvalidArgument     = "invalid_argument"
+	CodeCommandFailed       = "command_failed"
+)
+
+// exitCodes maps error codes to process exit codes
+var exitCodes = map[string]int{
+	CodeError:               1,
+	CodePermissionDenied:    2,
+	CodeStillRunning:        3,
+	CodeNotFound:            4,
+	CodeTimeout:             5,
+	CodeUnsupportedPlatform: 6,
+	CodePartialResults:      7,
+	CodeInvalidArgument:     8,
+}
+
+// Error is a command failure with a stable code and exit code
+type Error struct {
+	Code    string
+	Message string
+	Exit    int   // Overrides the code's exit code (used for command_failed)
+	Quiet   bool  // Don't print the error, only exit (--quiet)
+	Err     error // Underlying error, if any
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for the error
+func (e *Error) ExitCode() int {
+	if e.Exit != 0 {
+		return e.Exit
+	}
+	if code, ok := exitCodes[e.Code]; ok {
+		return code
+	}
+	return 1
+}
+
+// newError returns an Error with a formatted message
+func newError(code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// invalidArgf returns an invalid_argument error
+func invalidArgf(format string, args ...any) *Error {
+	return newError(CodeInvalidArgument, format, args...)
+}
+
+// usageError returns an invalid_argument error for a bad flag or argument,
+// pointing at the command's help
+func usageError(cmd *cobra.Command, err error) *Error {
+	return invalidArgf("%s\nRun '%s --help' for usage.", err, cmd.CommandPath())
+}
+
+// checkArgs wraps a cobra argument validator, such as cobra.ExactArgs(1),
+// so that a wrong argument count is an invalid_argument error
+func checkArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return usageError(cmd, err)
+		}
+		return nil
+	}
+}
+
+// classify converts any error into an Error, recognizing the sentinel
+// errors of the scanner and kill packages
+func classify(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	code := CodeError
+	switch {
+	case errors.Is(err, scanner.ErrUnsupportedPlatform), errors.Is(err, scanner.ErrNotImplemented):
+		code = CodeUnsupportedPlatform
+	case errors.Is(err, kill.ErrPermissionDenied), errors.Is(err, os.ErrPermission):
+		code = CodePermissionDenied
+	case errors.Is(err, kill.ErrProcessNotFound):
+		code = CodeNotFound
+	case errors.Is(err, kill.ErrProcessRunning):
+		code = CodeStillRunning
+	}
+	return &Error{Code: code, Message: err.Error(), Err: err}
+}
+
+// Execute runs the root command and returns the process exit code. Errors
+// are written to stderr, as a JSON ErrorResult when JSON output was asked
+// for.
+func Execute() int {
+	RootCmd.SilenceErrors = true
+	RootCmd.SilenceUsage = true
+	RootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError(cmd, err)
+	})
+
+	err := RootCmd.Execute()
+	if err == nil {
+		return 0
+	}
+
+	e := classify(err)
+	if !e.Quiet {
+		writeError(os.Stderr, e)
+	}
+	return e.ExitCode()
+}
+
+// writeError prints an error as text or, with JSON output, as an
+// ErrorResult
+func writeError(w io.Writer, e *Error) {
+	if !jsonRequested() {
+		fmt.Fprintf(w, "Error: %s\n", e.Message)
+		return
+	}
+
+	result := model.ErrorResult{
+		SchemaVersion: model.ErrorSchemaVersion,
+		Error: model.ErrorDetail{
+			Code:     e.Code,
+			Message:  e.Message,
+			ExitCode: e.ExitCode(),
+		},
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		fmt.Fprintf(w, "Error: %s\n", e.Message)
+		return
+	}
+	fmt.Fprintln(w, string(data))
+}
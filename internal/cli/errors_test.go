@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestArgumentCountErrors(t *testing.T) {
+	tests := [][]string{
+		{"pid"},
+		{"find", "node", "python"},
+		{"watch"},
+		{"schema", "scan-result", "error"},
+		{"config", "show", "extra"},
+	}
+	for _, args := range tests {
+		_, err := run(t, args...)
+		var e *Error
+		if !errors.As(err, &e) || e.Code != CodeInvalidArgument || e.ExitCode() != 8 {
+			t.Errorf("portman %s: error = %v, want an invalid argument error", strings.Join(args, " "), err)
+			continue
+		}
+		if !strings.Contains(e.Message, "--help' for usage") {
+			t.Errorf("portman %s: message = %q, want a pointer to --help", strings.Join(args, " "), e.Message)
+		}
+	}
+}
@@ -2,46 +2,61 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
-	"github.com/tasnimzotder/portman/internal/output"
 	"github.com/tasnimzotder/portman/internal/scanner"
+	"github.com/tasnimzotder/portman/internal/ui"
 )
 
 var findCmd = &cobra.Command{
 	Use:   "find <pattern>",
 	Short: "Find ports by process name, command, or user",
-	Args:  cobra.ExactArgs(1),
+	Args:  checkArgs(cobra.ExactArgs(1)),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		opts := scanner.DefaultOptions()
-		s, err := scanner.New(opts)
+		out, err := parseOutput()
 		if err != nil {
 			return err
 		}
 
+		s, err := scanner.New(out.scanOptions())
+		if err != nil {
+			return err
+		}
+
+		if watchMode {
+			cfg := ui.WatchConfig{
+				Scanner:  s,
+				Interval: watchInterval,
+				SortBy:   sortBy,
+				TCPOnly:  tcpOnly,
+				UDPOnly:  udpOnly,
+				Pattern:  args[0],
+				LogLines: logLines,
+				LogFile:  logFile,
+				History:  historyWindow,
+				Columns:  out.watchColumns(),
+				Filter:   out.filter,
+			}
+			if streamEvents() {
+				return ui.StreamEvents(cfg, os.Stdout)
+			}
+			return ui.RunWatch(cfg)
+		}
+
 		listeners, err := s.FindByPattern(args[0])
 		if err != nil {
 			return err
 		}
+		listeners = out.filter.Filter(listeners)
 
-		if len(listeners) == 0 {
+		// Other formats print an empty result
+		if len(listeners) == 0 && out.formatter() == nil {
 			fmt.Printf("No ports found matching '%s'\n", args[0])
 			return nil
 		}
 
-		if jsonOutput {
-			formatter := output.NewJSONFormatter(true)
-			out, err := formatter.Format(listeners)
-			if err != nil {
-				return err
-			}
-			fmt.Println(out)
-		} else {
-			formatter := output.NewTableFormatter()
-			formatter.NoHeader = noHeader
-			fmt.Print(formatter.Format(listeners))
-		}
-
-		return nil
+		out.sort.Sort(listeners)
+		return out.printListeners(listeners)
 	},
 }
@@ -3,13 +3,13 @@ package cli
 import (
 	"errors"
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tasnimzotder/portman/internal/kill"
+	"github.com/tasnimzotder/portman/internal/model"
 	"github.com/tasnimzotder/portman/internal/scanner"
 	"github.com/tasnimzotder/portman/internal/ui"
 )
@@ -26,64 +26,157 @@ func init() {
 	killCmd.Flags().BoolVarP(&killForce, "force", "f", false, "Use SIGKILL instead of SIGTERM")
 	killCmd.Flags().BoolVarP(&killYes, "yes", "y", false, "Skip confirmation")
 	killCmd.Flags().StringVarP(&killSignal, "signal", "s", "TERM", "Signal to send (HUP, INT, TERM, KILL)")
-	killCmd.Flags().DurationVar(&killTimeout, "timeout", 5*time.Second, "Wait time before SIGKILL (with --force)")
+	killCmd.Flags().DurationVar(&killTimeout, "timeout", kill.DefaultTimeout, "How long to wait for the process to exit")
 	killCmd.Flags().BoolVarP(&killQuiet, "quiet", "q", false, "No output on success")
 }
 
 var killCmd = &cobra.Command{
-	Use:   "kill <port>",
-	Short: "Kill the process using a port",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runKill,
+	Use:   "kill [port|range|host:port|service]...",
+	Short: "Kill the processes using ports",
+	Long: `Kill the processes using one or more ports.
+
+Ports may be numbers, ranges (3000-3010), comma-separated lists (80,443),
+host:port forms (127.0.0.1:8080) or service names (postgresql). All
+processes are shown and confirmed at once; a process listening on several
+of the ports is signalled once.
+
+With --project, only processes started in that project are killed, and
+without ports every one of its listeners is:
+
+  portman kill --project .    # The servers of this checkout`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && projectDir == "" {
+			return invalidArgf("kill needs at least one port, or --project")
+		}
+		return nil
+	},
+	RunE: runKill,
 }
 
 func runKill(cmd *cobra.Command, args []string) error {
-	port, err := parsePort(args[0])
+	var targets []portTarget
+	if len(args) > 0 {
+		var err error
+		if targets, err = parseTargets(args); err != nil {
+			return err
+		}
+	}
+	proj, err := parseProject()
 	if err != nil {
 		return err
 	}
 
-	// Find process using the port
-	opts := scanner.DefaultOptions()
+	// Determine signal to use
+	killOpts, ok := kill.NewOptions(killSignal, killForce, killTimeout)
+	if !ok {
+		return invalidArgf("unknown signal: %s", killSignal)
+	}
+
+	// Find processes using the ports
+	opts := scanDefaults()
+	opts.FetchContext = proj != nil
 	s, err := scanner.New(opts)
 	if err != nil {
 		return err
 	}
 
-	listener, err := s.GetPort(port)
+	if len(targets) == 0 {
+		return killProject(s, proj, killOpts)
+	}
+
+	var listeners []model.Listener
+	var free []portTarget
+	for _, t := range targets {
+		l, err := lookupTarget(s, t)
+		if err != nil {
+			return err
+		}
+		if l == nil {
+			free = append(free, t)
+			continue
+		}
+		listeners = append(listeners, *l)
+	}
+
+	if len(listeners) == 0 {
+		if len(free) == 1 {
+			return newError(CodeNotFound, "port %s is not in use", free[0])
+		}
+		return newError(CodeNotFound, "ports %s are not in use", joinTargets(free))
+	}
+	if len(free) > 0 && !killQuiet {
+		fmt.Printf("Not in use: %s\n", joinTargets(free))
+	}
+
+	if proj != nil {
+		var others []string
+		listeners, others = splitProject(listeners, proj)
+		if len(listeners) == 0 {
+			return newError(CodeNotFound, "none of the processes on %s were started in project %s", joinTargets(targets), proj.Root)
+		}
+		if len(others) > 0 && !killQuiet {
+			fmt.Printf("Not in project %s: %s\n", proj.Name, strings.Join(others, ", "))
+		}
+	}
+
+	return killListeners(listeners, killOpts)
+}
+
+// killProject kills every process listening from a project
+func killProject(s scanner.Scanner, proj *model.Project, killOpts kill.Options) error {
+	all, err := s.ListListeners()
 	if err != nil {
 		return err
 	}
-
-	if listener == nil {
-		fmt.Printf("Port %d is not in use.\n", port)
-		os.Exit(1)
+	listeners, _ := splitProject(all, proj)
+	if len(listeners) == 0 {
+		return newError(CodeNotFound, "no listeners were started in project %s", proj.Root)
 	}
+	return killListeners(listeners, killOpts)
+}
 
-	pid := listener.PID
-	processName := "unknown"
-	userName := "unknown"
-	uptime := ""
+// splitProject returns the listeners started in proj, and the ports of the
+// others
+func splitProject(listeners []model.Listener, proj *model.Project) ([]model.Listener, []string) {
+	match := inProject(proj)
+	var in []model.Listener
+	var others []string
+	for _, l := range listeners {
+		if match(l) {
+			in = append(in, l)
+		} else {
+			others = append(others, strconv.Itoa(l.Port))
+		}
+	}
+	return in, others
+}
 
-	if listener.Process != nil {
-		processName = listener.Process.Command
-		if processName == "" {
-			processName = listener.Process.Name
+// killListeners confirms and signals the processes of listeners
+func killListeners(listeners []model.Listener, killOpts kill.Options) error {
+	// Signal each process once, even if it listens on several ports. Ports
+	// the kernel forwards to a container have no process to signal.
+	var pids []int
+	seen := make(map[int]bool)
+	for _, l := range listeners {
+		if l.PID <= 0 {
+			continue
 		}
-		userName = listener.Process.User
-		if listener.Process.UptimeSeconds > 0 {
-			uptime = (time.Duration(listener.Process.UptimeSeconds) * time.Second).String()
+		if !seen[l.PID] {
+			seen[l.PID] = true
+			pids = append(pids, l.PID)
 		}
 	}
 
+	if len(pids) == 0 {
+		return newError(CodeNotFound, "no process to kill: the ports are forwarded to containers by the kernel; stop the containers instead")
+	}
+
 	// Show confirmation unless --yes
 	if !killYes {
-		fmt.Printf("Kill process on port %d?\n", port)
-		fmt.Printf("  Process: %s\n", processName)
-		fmt.Printf("  PID:     %d\n", pid)
-		fmt.Printf("  User:    %s\n", userName)
-		if uptime != "" {
-			fmt.Printf("  Uptime:  %s\n", uptime)
+		if len(listeners) == 1 {
+			fmt.Print(ui.KillSummary(listeners[0]))
+		} else {
+			fmt.Print(ui.KillSummaryAll(listeners))
 		}
 		fmt.Println()
 
@@ -93,19 +186,32 @@ func runKill(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Determine signal to use
-	var sig syscall.Signal
-	if killForce {
-		sig = syscall.SIGKILL
-	} else {
-		var ok bool
-		sig, ok = kill.ParseSignal(killSignal)
-		if !ok {
-			return fmt.Errorf("unknown signal: %s", killSignal)
+	var failures []*Error
+	for _, pid := range pids {
+		if err := killPID(pid, killOpts); err != nil {
+			e := classify(err)
+			if len(pids) == 1 {
+				return e
+			}
+			if !killQuiet {
+				fmt.Printf("PID %d: %s\n", pid, e.Message)
+			}
+			failures = append(failures, e)
 		}
 	}
 
-	// Send the signal
+	switch {
+	case len(failures) == 0:
+		return nil
+	case len(failures) == len(pids):
+		return &Error{Code: failures[0].Code, Message: fmt.Sprintf("could not kill any of %d processes", len(pids)), Err: failures[0]}
+	default:
+		return newError(CodePartialResults, "killed %d of %d processes", len(pids)-len(failures), len(pids))
+	}
+}
+
+// killPID sends the signal to one process and waits for it to exit
+func killPID(pid int, opts kill.Options) error {
 	if !killQuiet {
 		signalName := strings.ToUpper(killSignal)
 		if killForce {
@@ -114,38 +220,18 @@ func runKill(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Sent SIG%s to PID %d\n", signalName, pid)
 	}
 
-	err = kill.Kill(pid, sig)
-	if err != nil {
-		if errors.Is(err, kill.ErrPermissionDenied) {
-			fmt.Println("Permission denied. Try running with sudo.")
-			os.Exit(2)
-		}
+	err := kill.Terminate(pid, opts)
+	switch {
+	case errors.Is(err, kill.ErrPermissionDenied):
+		return &Error{Code: CodePermissionDenied, Message: fmt.Sprintf("permission denied to signal PID %d; try running with sudo", pid), Err: err}
+	case errors.Is(err, kill.ErrProcessRunning):
+		return &Error{Code: CodeStillRunning, Message: fmt.Sprintf("process %d didn't terminate", pid), Err: err}
+	case err != nil:
 		return err
 	}
 
-	// Wait for process to exit
-	if kill.WaitForExit(pid, 3*time.Second) {
-		if !killQuiet {
-			fmt.Println("Process terminated.")
-		}
-		return nil
-	}
-
-	// If --force, try SIGKILL after timeout
-	if killForce && sig != syscall.SIGKILL {
-		if !killQuiet {
-			fmt.Printf("Process didn't exit, sending SIGKILL...\n")
-		}
-		kill.Kill(pid, syscall.SIGKILL)
-		if kill.WaitForExit(pid, 2*time.Second) {
-			if !killQuiet {
-				fmt.Println("Process killed.")
-			}
-			return nil
-		}
+	if !killQuiet {
+		fmt.Println("Process terminated.")
 	}
-
-	fmt.Println("Process didn't terminate.")
-	os.Exit(3)
 	return nil
 }
@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/filter"
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/output"
+	"github.com/tasnimzotder/portman/internal/project"
+	"github.com/tasnimzotder/portman/internal/scanner"
+)
+
+var (
+	outputFormat string // Value of the -o flag
+	templateFile string // Template read from a file, like -o template=
+	filterExpr   string // Value of the --filter flag
+	projectDir   string // Value of the --project flag
+)
+
+// outputSpec is a parsed -o value
+type outputSpec struct {
+	format   string                    // "table", "json", "csv", "tsv", "yaml", or "template"
+	columns  []output.Column           // Table columns, nil for the default layout
+	template *output.TemplateFormatter // Set for the template format
+	filter   *filter.Expr              // --filter expression, nil to show all
+	sort     output.SortOrder          // --sort order
+	groupBy  string                    // --group-by key, "" for a flat list
+}
+
+// parseOutput parses the -o flag: "table", "json", "csv", "tsv", "yaml",
+// "columns=a,b,c", or "template=<text>". --json is an alias for -o json,
+// and --template-file for -o template=. The spec also carries --filter,
+// narrowed by --project, and --sort.
+func parseOutput() (outputSpec, error) {
+	spec := outputSpec{format: "table"}
+
+	expr, err := parseFilter()
+	if err != nil {
+		return spec, err
+	}
+	spec.filter = expr
+
+	proj, err := parseProject()
+	if err != nil {
+		return spec, err
+	}
+	if proj != nil {
+		spec.filter = spec.filter.And("project "+proj.Name, inProject(proj), true)
+	}
+
+	order, err := parseSort()
+	if err != nil {
+		return spec, err
+	}
+	spec.sort = order
+
+	if groupBy != "" {
+		spec.groupBy = strings.ToLower(groupBy)
+		if !output.ValidGroupBy(spec.groupBy) {
+			return spec, invalidArgf("unknown --group-by value: %s (use %s)", groupBy, strings.Join(output.GroupByNames(), ", "))
+		}
+		if watchMode {
+			return spec, invalidArgf("--group-by can't be combined with --watch")
+		}
+	}
+
+	if jsonRequested() {
+		spec.format = "json"
+		return spec, nil
+	}
+
+	if templateFile != "" {
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			return spec, &Error{Code: CodeInvalidArgument, Message: fmt.Sprintf("reading template file: %s", err), Err: err}
+		}
+		return spec.withTemplate(string(data))
+	}
+
+	name, value, _ := strings.Cut(outputFormat, "=")
+	switch strings.ToLower(name) {
+	case "", "table":
+	case "json", "csv", "tsv", "yaml":
+		spec.format = strings.ToLower(name)
+	case "yml":
+		spec.format = "yaml"
+	case "columns", "cols":
+		columns, err := output.ParseColumns(value)
+		if err != nil {
+			return spec, &Error{Code: CodeInvalidArgument, Message: err.Error(), Err: err}
+		}
+		spec.columns = columns
+	case "template", "tmpl":
+		return spec.withTemplate(value)
+	default:
+		return spec, invalidArgf("unknown output format: %s (use table, json, csv, tsv, yaml, columns=..., or template=...)", outputFormat)
+	}
+
+	if spec.groupBy != "" && spec.format != "table" && spec.format != "yaml" {
+		return spec, invalidArgf("--group-by works with table, json and yaml output, not %s", spec.format)
+	}
+	return spec, nil
+}
+
+// parseFilter parses the --filter flag, returning nil when it isn't set
+func parseFilter() (*filter.Expr, error) {
+	if filterExpr == "" {
+		return nil, nil
+	}
+	expr, err := filter.Parse(filterExpr)
+	if err != nil {
+		return nil, &Error{Code: CodeInvalidArgument, Message: err.Error(), Err: err}
+	}
+	return expr, nil
+}
+
+// parseProject resolves the --project directory to its project, returning
+// nil when the flag isn't set
+func parseProject() (*model.Project, error) {
+	if projectDir == "" {
+		return nil, nil
+	}
+	proj := project.Resolve(projectDir)
+	if proj == nil {
+		return nil, invalidArgf("no project found at %s (looked for %s in it and its parents)", projectDir, strings.Join(project.Markers, ", "))
+	}
+	return proj, nil
+}
+
+// inProject matches listeners whose process runs in proj
+func inProject(proj *model.Project) func(l model.Listener) bool {
+	return func(l model.Listener) bool {
+		return l.Process != nil && project.Contains(proj, l.Process.Project)
+	}
+}
+
+// parseSort parses the --sort flag
+func parseSort() (output.SortOrder, error) {
+	order, err := output.ParseSort(sortBy)
+	if err != nil {
+		return nil, &Error{Code: CodeInvalidArgument, Message: err.Error(), Err: err}
+	}
+	return order, nil
+}
+
+// jsonRequested reports whether JSON output was asked for, with --json or
+// -o json
+func jsonRequested() bool {
+	return jsonOutput || strings.EqualFold(outputFormat, "json")
+}
+
+// withTemplate switches the spec to the template format
+func (o outputSpec) withTemplate(text string) (outputSpec, error) {
+	tmpl, err := output.NewTemplateFormatter(text)
+	if err != nil {
+		return o, &Error{Code: CodeInvalidArgument, Message: err.Error(), Err: err}
+	}
+	o.format = "template"
+	o.template = tmpl
+	return o, nil
+}
+
+// scanOptions returns scanner options for the output, fetching process
+// stats with --stats, for --group-by memory totals, or when a chosen column,
+// the template, the filter, or the sort order needs them, and launch context
+// for structured output, the PROJECT column, and project filters
+func (o outputSpec) scanOptions() scanner.Options {
+	opts := scanDefaults()
+	if fetchStats || o.groupBy != "" || output.NeedStats(o.columns) || (o.template != nil && o.template.UsesStats()) || o.filter.NeedsStats() || o.sort.NeedsStats() {
+		opts.FetchStats = true
+	}
+	if o.format != "table" || output.NeedContext(o.columns) || o.filter.NeedsContext() {
+		opts.FetchContext = true
+	}
+	return opts
+}
+
+// tableColumns returns the columns of table output
+func (o outputSpec) tableColumns() []output.Column {
+	if o.columns != nil {
+		return o.columns
+	}
+	return defaultColumns(output.DefaultColumns)
+}
+
+// watchColumns returns the columns of the all-ports watch view
+func (o outputSpec) watchColumns() []output.Column {
+	if o.columns != nil {
+		return o.columns
+	}
+	return defaultColumns(output.DefaultWatchColumns)
+}
+
+// defaultColumns resolves a default layout, adding SERVICE after PORT with
+// --services
+func defaultColumns(names []string) []output.Column {
+	if showServices {
+		names = append([]string{names[0], "service"}, names[1:]...)
+	}
+	columns, _ := output.LookupColumns(names)
+	return columns
+}
+
+// listenerFormatter is implemented by every formatter except the table
+type listenerFormatter interface {
+	Format(listeners []model.Listener) (string, error)
+	FormatSingle(listener *model.Listener) (string, error)
+}
+
+// formatter returns the formatter for the chosen format, or nil for tables
+func (o outputSpec) formatter() listenerFormatter {
+	switch o.format {
+	case "json":
+		return output.NewJSONFormatter(true)
+	case "csv", "tsv":
+		f := output.NewCSVFormatter()
+		f.TSV = o.format == "tsv"
+		f.NoHeader = noHeader
+		return f
+	case "yaml":
+		return output.NewYAMLFormatter()
+	case "template":
+		return o.template
+	}
+	return nil
+}
+
+// printListeners writes listeners in the chosen format, grouped with
+// --group-by
+func (o outputSpec) printListeners(listeners []model.Listener) error {
+	if o.groupBy != "" {
+		return o.printGroups(output.GroupListeners(listeners, o.groupBy))
+	}
+
+	if f := o.formatter(); f != nil {
+		out, err := f.Format(listeners)
+		if err != nil {
+			return err
+		}
+		printOutput(out)
+		return nil
+	}
+
+	formatter := output.NewTableFormatter()
+	formatter.NoHeader = noHeader
+	formatter.Columns = o.tableColumns()
+	fmt.Print(formatter.Format(listeners))
+	return nil
+}
+
+// printGroups writes --group-by groups as JSON, YAML, or a table tree
+func (o outputSpec) printGroups(groups []model.ListenerGroup) error {
+	var out string
+	var err error
+	switch o.format {
+	case "json":
+		out, err = output.NewJSONFormatter(true).FormatGroups(groups, o.groupBy)
+	case "yaml":
+		out, err = output.NewYAMLFormatter().FormatGroups(groups, o.groupBy)
+	}
+	if err != nil {
+		return err
+	}
+	if out != "" {
+		printOutput(out)
+		return nil
+	}
+
+	formatter := output.NewTableFormatter()
+	formatter.NoHeader = noHeader
+	formatter.Columns = o.tableColumns()
+	fmt.Print(formatter.FormatGroups(groups, o.groupBy))
+	return nil
+}
+
+// printDetail writes a single port's detail in the chosen format. A nil
+// listener means the port is not in use; templates have nothing to render
+// then, so that goes to stderr.
+func (o outputSpec) printDetail(target portTarget, listener *model.Listener) error {
+	if o.format == "template" && listener == nil {
+		fmt.Fprintf(os.Stderr, "Port %s is not in use.\n", target)
+		return nil
+	}
+	if f := o.formatter(); f != nil {
+		out, err := f.FormatSingle(listener)
+		if err != nil {
+			return err
+		}
+		printOutput(out)
+		return nil
+	}
+
+	if listener == nil {
+		fmt.Printf("Port %s is not in use.\n", target)
+		return nil
+	}
+	formatter := output.NewTableFormatter()
+	fmt.Print(formatter.FormatDetail(listener))
+	return nil
+}
+
+// printOutput prints formatter output, ending it with a newline
+func printOutput(out string) {
+	if out == "" || strings.HasSuffix(out, "\n") {
+		fmt.Print(out)
+	} else {
+		fmt.Println(out)
+	}
+}
@@ -6,22 +6,25 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tasnimzotder/portman/internal/model"
-	"github.com/tasnimzotder/portman/internal/output"
 	"github.com/tasnimzotder/portman/internal/scanner"
 )
 
 var pidCmd = &cobra.Command{
 	Use:   "pid <pid>",
 	Short: "Show all ports used by a specific process ID",
-	Args:  cobra.ExactArgs(1),
+	Args:  checkArgs(cobra.ExactArgs(1)),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pid, err := strconv.Atoi(args[0])
 		if err != nil {
-			return fmt.Errorf("invalid pid: %s", args[0])
+			return invalidArgf("invalid pid: %s", args[0])
 		}
 
-		opts := scanner.DefaultOptions()
-		s, err := scanner.New(opts)
+		out, err := parseOutput()
+		if err != nil {
+			return err
+		}
+
+		s, err := scanner.New(out.scanOptions())
 		if err != nil {
 			return err
 		}
@@ -33,31 +36,19 @@ var pidCmd = &cobra.Command{
 
 		var matches []model.Listener
 		for _, l := range listeners {
-			if l.PID == pid {
+			if l.PID == pid && out.filter.Match(l) {
 				matches = append(matches, l)
 			}
 		}
 
-		if len(matches) == 0 {
+		// Other formats print an empty result
+		if len(matches) == 0 && out.formatter() == nil {
 			fmt.Printf("No ports found for PID %d\n", pid)
 			return nil
 		}
 
-		output.SortListeners(matches, sortBy)
-
-		if jsonOutput {
-			formatter := output.NewJSONFormatter(true)
-			out, err := formatter.Format(matches)
-			if err != nil {
-				return err
-			}
-			fmt.Println(out)
-		} else {
-			formatter := output.NewTableFormatter()
-			formatter.NoHeader = noHeader
-			fmt.Print(formatter.Format(matches))
-		}
+		out.sort.Sort(matches)
 
-		return nil
+		return out.printListeners(matches)
 	},
 }
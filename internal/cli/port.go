@@ -3,33 +3,30 @@ package cli
 import (
 	"github.com/spf13/cobra"
 	"github.com/tasnimzotder/portman/internal/scanner"
-	"github.com/tasnimzotder/portman/internal/ui"
 )
 
 var portCmd = &cobra.Command{
-	Use:   "port <port>",
+	Use:   "port <port|range|host:port|service>...",
 	Short: "Show detailed information about a specific port",
-	Args:  cobra.ExactArgs(1),
+	Args:  checkArgs(cobra.MinimumNArgs(1)),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		port, err := parsePort(args[0])
+		targets, err := parseTargets(args)
 		if err != nil {
 			return err
 		}
 
-		opts := scanner.DefaultOptions()
-		s, err := scanner.New(opts)
+		out, err := parseOutput()
 		if err != nil {
 			return err
 		}
 
-		if watchMode {
-			return ui.RunWatchPort(ui.WatchPortConfig{
-				Scanner:  s,
-				Port:     port,
-				Interval: watchInterval,
-			})
+		opts := out.scanOptions()
+		opts.FetchContext = !watchMode // For the detail view
+		s, err := scanner.New(opts)
+		if err != nil {
+			return err
 		}
 
-		return showPortDetail(s, port)
+		return showPorts(s, targets, out)
 	},
 }
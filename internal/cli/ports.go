@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/scanner"
+	"github.com/tasnimzotder/portman/internal/services"
+)
+
+// maxPortArgs bounds how many ports arguments may expand to
+const maxPortArgs = 1024
+
+// portTarget is a port from the command line, optionally limited to one
+// host address
+type portTarget struct {
+	Host string // Empty for any address
+	Port int
+}
+
+func (t portTarget) String() string {
+	if t.Host == "" {
+		return strconv.Itoa(t.Port)
+	}
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// parsePort validates and returns a port number
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, invalidArgf("invalid port: %s", s)
+	}
+	if port < 1 || port > 65535 {
+		return 0, invalidArgf("port must be between 1 and 65535")
+	}
+	return port, nil
+}
+
+// parseTargets parses port arguments: numbers, ranges such as "3000-3010",
+// comma-separated lists such as "80,443", "host:port" forms such as
+// "127.0.0.1:8080" or "[::1]:8080", service names from /etc/services such
+// as "postgresql", and aliases and @groups from the config file. Targets
+// are returned in order without duplicates. No arguments give no targets,
+// but arguments that expand to no ports, such as an empty group, are an
+// error.
+func parseTargets(args []string) ([]portTarget, error) {
+	var targets []portTarget
+	seen := make(map[portTarget]bool)
+
+	for _, arg := range args {
+		items, err := expandItems(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			host, spec := splitHost(item)
+			ports, err := parsePortSpec(spec)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, port := range ports {
+				t := portTarget{Host: host, Port: port}
+				if seen[t] {
+					continue
+				}
+				seen[t] = true
+				targets = append(targets, t)
+				if len(targets) > maxPortArgs {
+					return nil, invalidArgf("too many ports (max %d)", maxPortArgs)
+				}
+			}
+		}
+	}
+
+	if len(targets) == 0 && len(args) > 0 {
+		return nil, invalidArgf("no ports given")
+	}
+	return targets, nil
+}
+
+// maxExpandDepth bounds how deeply groups and aliases may refer to each
+// other, which also stops cycles
+const maxExpandDepth = 8
+
+// expandItems splits an argument at commas, replacing @group references and
+// aliases from the config file with the arguments they stand for
+func expandItems(arg string, depth int) ([]string, error) {
+	if depth > maxExpandDepth {
+		return nil, invalidArgf("port groups and aliases nest too deeply at %s", arg)
+	}
+
+	var items []string
+	for _, item := range strings.Split(arg, ",") {
+		item = strings.TrimSpace(item)
+		var refs []string
+		switch {
+		case item == "":
+			continue
+		case strings.HasPrefix(item, "@"):
+			group, ok := settings.Group(item[1:])
+			if !ok {
+				return nil, invalidArgf("unknown port group: %s", item)
+			}
+			refs = group
+		default:
+			alias, ok := settings.Alias(item)
+			if !ok {
+				items = append(items, item)
+				continue
+			}
+			refs = []string{alias}
+		}
+
+		for _, ref := range refs {
+			expanded, err := expandItems(ref, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, expanded...)
+		}
+	}
+	return items, nil
+}
+
+// parsePortArgs is parseTargets for commands that ignore hosts, returning
+// just the ports
+func parsePortArgs(args []string) ([]int, error) {
+	targets, err := parseTargets(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var ports []int
+	seen := make(map[int]bool)
+	for _, t := range targets {
+		if !seen[t.Port] {
+			seen[t.Port] = true
+			ports = append(ports, t.Port)
+		}
+	}
+	return ports, nil
+}
+
+// splitHost splits "host:port" and "[v6]:port" into host and port parts.
+// Anything without a host is returned whole.
+func splitHost(item string) (string, string) {
+	if host, port, err := net.SplitHostPort(item); err == nil {
+		return host, port
+	}
+	return "", item
+}
+
+// parsePortSpec parses a port number, a range, or a service name
+func parsePortSpec(spec string) ([]int, error) {
+	if _, err := strconv.Atoi(spec); err == nil {
+		port, err := parsePort(spec)
+		if err != nil {
+			return nil, err
+		}
+		return []int{port}, nil
+	}
+
+	if lo, hi, ok := strings.Cut(spec, "-"); ok && isNumber(lo) && isNumber(hi) {
+		start, err := parsePort(lo)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parsePort(hi)
+		if err != nil {
+			return nil, err
+		}
+		if end < start {
+			return nil, invalidArgf("invalid port range: %s", spec)
+		}
+		if end-start >= maxPortArgs {
+			return nil, invalidArgf("too many ports (max %d)", maxPortArgs)
+		}
+
+		ports := make([]int, 0, end-start+1)
+		for port := start; port <= end; port++ {
+			ports = append(ports, port)
+		}
+		return ports, nil
+	}
+
+	if ports := services.Ports(spec); len(ports) > 0 {
+		return ports, nil
+	}
+	return nil, invalidArgf("invalid port: %s (not a number, range, or service name)", spec)
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// matchesHost reports whether a listener serves a target's host: the same
+// address, or a wildcard bind that serves every address
+func matchesHost(l *model.Listener, host string) bool {
+	switch {
+	case host == "":
+		return true
+	case l.Address == host:
+		return true
+	case l.Address == "0.0.0.0" || l.Address == "*" || l.Address == "::":
+		return true
+	case strings.EqualFold(host, "localhost"):
+		return l.Address == "127.0.0.1" || l.Address == "::1"
+	}
+	return false
+}
+
+// lookupTarget returns the listener on a target, or nil when the port is
+// not in use on the target's host
+func lookupTarget(s scanner.Scanner, t portTarget) (*model.Listener, error) {
+	l, err := s.GetPort(t.Port)
+	if err != nil || l == nil {
+		return nil, err
+	}
+	if !matchesHost(l, t.Host) {
+		return nil, nil
+	}
+	return l, nil
+}
+
+// hostScanner limits GetPort to one host, for commands such as wait that
+// poll through a scanner
+type hostScanner struct {
+	scanner.Scanner
+	host string
+}
+
+func (h hostScanner) GetPort(port int) (*model.Listener, error) {
+	return lookupTarget(h.Scanner, portTarget{Host: h.host, Port: port})
+}
+
+// targetScanner limits GetPort to the host each port was given with, for
+// views that look up several targets by port
+type targetScanner struct {
+	scanner.Scanner
+	hosts map[int]string // Ports without a host match any address
+}
+
+func (t targetScanner) GetPort(port int) (*model.Listener, error) {
+	return hostScanner{Scanner: t.Scanner, host: t.hosts[port]}.GetPort(port)
+}
+
+// joinTargets formats targets as a comma-separated list
+func joinTargets(targets []portTarget) string {
+	parts := make([]string, len(targets))
+	for i, t := range targets {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeTargets is joinTargets with a "port" or "ports" prefix
+func describeTargets(targets []portTarget) string {
+	if len(targets) == 1 {
+		return fmt.Sprintf("port %s", targets[0])
+	}
+	return fmt.Sprintf("ports %s", joinTargets(targets))
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/config"
+)
+
+// withSettings replaces the loaded configuration for a test
+func withSettings(t *testing.T, aliases, groups map[string][]string) {
+	t.Helper()
+	c := &config.Config{Aliases: make(map[string]config.Setting), Groups: make(map[string]config.Setting)}
+	for name, v := range aliases {
+		c.Aliases[name] = config.Setting{Value: v}
+	}
+	for name, v := range groups {
+		c.Groups[name] = config.Setting{Value: v}
+	}
+	prev := settings
+	settings = c
+	t.Cleanup(func() { settings = prev })
+}
+
+func TestParseTargets(t *testing.T) {
+	withSettings(t,
+		map[string][]string{
+			"api":  {"8080"},
+			"db":   {"localhost:5432"},
+			"main": {"api"},
+		},
+		map[string][]string{
+			"stack":  {"3000", "api", "db"},
+			"all":    {"@stack", "9000-9001"},
+			"nested": {"main"},
+		},
+	)
+
+	tests := []struct {
+		args []string
+		want []portTarget
+	}{
+		{nil, nil},
+		{[]string{"80"}, []portTarget{{Port: 80}}},
+		{[]string{"3000-3002"}, []portTarget{{Port: 3000}, {Port: 3001}, {Port: 3002}}},
+		{[]string{"80,443", "80"}, []portTarget{{Port: 80}, {Port: 443}}},
+		{[]string{"127.0.0.1:8080"}, []portTarget{{Host: "127.0.0.1", Port: 8080}}},
+		{[]string{"[::1]:8080"}, []portTarget{{Host: "::1", Port: 8080}}},
+		{[]string{"api"}, []portTarget{{Port: 8080}}},
+		{[]string{"db"}, []portTarget{{Host: "localhost", Port: 5432}}},
+		{[]string{"main"}, []portTarget{{Port: 8080}}},
+		{[]string{"@nested"}, []portTarget{{Port: 8080}}},
+		{[]string{"@stack"}, []portTarget{{Port: 3000}, {Port: 8080}, {Host: "localhost", Port: 5432}}},
+		{[]string{"@all"}, []portTarget{{Port: 3000}, {Port: 8080}, {Host: "localhost", Port: 5432}, {Port: 9000}, {Port: 9001}}},
+		{[]string{"api", "8080"}, []portTarget{{Port: 8080}}},
+	}
+	for _, tt := range tests {
+		got, err := parseTargets(tt.args)
+		if err != nil {
+			t.Errorf("parseTargets(%q): %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTargets(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestParseTargetsErrors(t *testing.T) {
+	withSettings(t, nil, map[string][]string{
+		"loop":  {"@loop"},
+		"empty": {","},
+	})
+
+	tests := [][]string{
+		{"0"},
+		{"65536"},
+		{"3005-3000"},
+		{"1-2000"},
+		{"notaport"},
+		{"@missing"},
+		{"@loop"},
+		{"@empty"},
+		{","},
+	}
+	for _, args := range tests {
+		_, err := parseTargets(args)
+		var e *Error
+		if !errors.As(err, &e) || e.Code != CodeInvalidArgument {
+			t.Errorf("parseTargets(%q) error = %v, want an invalid argument error", args, err)
+		}
+	}
+}
+
+func TestLocalConfigCantKill(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(config.LocalName, []byte("defaults:\n  kill.yes: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	err = loadConfig(killCmd)
+	os.Stderr = stderr
+	w.Close()
+	msg, _ := io.ReadAll(r)
+
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v, want a warning only", err)
+	}
+	if killYes {
+		t.Error("kill.yes from .portman.yaml was applied")
+	}
+	if !strings.Contains(string(msg), "Warning: config: ") || !strings.Contains(string(msg), "kill.yes can only be set in") {
+		t.Errorf("loadConfig() warned %q, want a warning about kill.yes", msg)
+	}
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/output"
+	"github.com/tasnimzotder/portman/internal/scanner"
+)
+
+var reportFormat string
+
+func init() {
+	reportCmd.Flags().StringVarP(&reportFormat, "format", "f", "md", "Report format: md, html")
+	RootCmd.AddCommand(reportCmd)
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report [port|range]...",
+	Short: "Generate a Markdown or HTML report of listening ports",
+	Long: `Generate a self-contained report with the host, scan time, all listeners,
+and exposure warnings. Ports given as arguments are included in detail, with
+their connections and process stats.`,
+	RunE: runReport,
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(reportFormat)
+	switch format {
+	case "md", "markdown":
+		format = "md"
+	case "html":
+	default:
+		return invalidArgf("unknown report format: %s (use md or html)", reportFormat)
+	}
+	for _, name := range []string{"output", "json", "template-file", "group-by", "watch"} {
+		if cmd.Flags().Changed(name) {
+			return invalidArgf("--%s doesn't apply to report (use --format md or html)", name)
+		}
+	}
+
+	ports, err := parsePortArgs(args)
+	if err != nil {
+		return err
+	}
+
+	expr, err := parseFilter()
+	if err != nil {
+		return err
+	}
+	proj, err := parseProject()
+	if err != nil {
+		return err
+	}
+	if proj != nil {
+		expr = expr.And("project "+proj.Name, inProject(proj), true)
+	}
+
+	order, err := parseSort()
+	if err != nil {
+		return err
+	}
+
+	opts := scanDefaults()
+	opts.FetchStats = fetchStats || expr.NeedsStats() || order.NeedsStats()
+	opts.FetchContext = expr.NeedsContext()
+	if tcpOnly {
+		opts.IncludeUDP = false
+	}
+	if udpOnly {
+		opts.IncludeTCP = false
+	}
+
+	s, err := scanner.New(opts)
+	if err != nil {
+		return err
+	}
+
+	listeners, err := s.ListListeners()
+	if err != nil {
+		return err
+	}
+	listeners = expr.Filter(listeners)
+	order.Sort(listeners)
+
+	var details []model.Listener
+	var missing, failed []int
+	for _, port := range ports {
+		l, err := s.GetPort(port)
+		if err != nil {
+			failed = append(failed, port)
+			continue
+		}
+		if l == nil {
+			missing = append(missing, port)
+			continue
+		}
+		details = append(details, *l)
+	}
+
+	report := output.NewReport(output.NewScanResult(listeners), details, missing)
+
+	if format == "html" {
+		html, err := report.HTML()
+		if err != nil {
+			return err
+		}
+		fmt.Print(html)
+	} else {
+		fmt.Print(report.Markdown())
+	}
+
+	if len(failed) > 0 {
+		return newError(CodePartialResults, "could not inspect %d of %d ports: %s", len(failed), len(ports), joinPorts(failed))
+	}
+	return nil
+}
+
+// joinPorts formats ports as a comma-separated list
+func joinPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = strconv.Itoa(port)
+	}
+	return strings.Join(parts, ", ")
+}
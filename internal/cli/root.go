@@ -2,12 +2,14 @@ package cli
 
 import (
 	"fmt"
-	"strconv"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/tasnimzotder/portman/internal/output"
+	"github.com/tasnimzotder/portman/internal/container"
+	"github.com/tasnimzotder/portman/internal/model"
 	"github.com/tasnimzotder/portman/internal/scanner"
+	"github.com/tasnimzotder/portman/internal/services"
 	"github.com/tasnimzotder/portman/internal/ui"
 )
 
@@ -20,25 +22,51 @@ var (
 	sortBy        string
 	watchMode     bool
 	watchInterval time.Duration
+	logLines      int
+	logFile       string
+	historyWindow time.Duration
+	showServices  bool
+	registryPath  string
+	fetchStats    bool
+	groupBy       string
+	dockerNames   bool
 )
 
 var RootCmd = &cobra.Command{
-	Use:   "portman [port]",
+	Use:   "portman [port|range|host:port|service]...",
 	Short: "See what's using your ports",
 	Long:  `portman is a cross-platform CLI tool for inspecting and managing network port usage.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runRoot,
+	Args:  cobra.ArbitraryArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		return loadRegistry()
+	},
+	RunE: runRoot,
 }
 
 func init() {
 	// Global flags
-	RootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
+	RootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON (alias for -o json)")
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "Output format: table, json, csv, tsv, yaml, columns=<list>, template=<text>")
+	RootCmd.PersistentFlags().StringVar(&filterExpr, "filter", "", `Only show listeners matching an expression, e.g. 'port>=3000 && user=="me"'`)
+	RootCmd.PersistentFlags().StringVar(&projectDir, "project", "", "Only show listeners started in the project containing a directory, e.g. '.'")
+	RootCmd.PersistentFlags().StringVar(&templateFile, "template-file", "", "Render output with a Go template read from a file")
 	RootCmd.PersistentFlags().BoolVar(&noHeader, "no-header", false, "Omit header row")
 	RootCmd.PersistentFlags().BoolVarP(&tcpOnly, "tcp", "t", false, "Show only TCP")
 	RootCmd.PersistentFlags().BoolVarP(&udpOnly, "udp", "u", false, "Show only UDP")
-	RootCmd.PersistentFlags().StringVar(&sortBy, "sort", "port", "Sort by: port, pid, user, conns")
+	RootCmd.PersistentFlags().StringVar(&sortBy, "sort", "port", "Sort by comma-separated keys, '-' for descending: port, pid, user, conns, uptime, rss, cpu, fds, address, proto, command")
 	RootCmd.PersistentFlags().BoolVarP(&watchMode, "watch", "w", false, "Live updating display")
 	RootCmd.PersistentFlags().DurationVar(&watchInterval, "interval", time.Second, "Watch refresh interval")
+	RootCmd.PersistentFlags().IntVar(&logLines, "log-lines", ui.DefaultLogLines, "Events shown in the watch log panel (0 to hide)")
+	RootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append watch events to a file")
+	RootCmd.PersistentFlags().DurationVar(&historyWindow, "history", 0, "Sparkline history window in port watch (default: whole session)")
+	RootCmd.PersistentFlags().StringVar(&groupBy, "group-by", "", "Show listeners as a tree grouped by: process, user, pid, container, cgroup")
+	RootCmd.PersistentFlags().BoolVar(&fetchStats, "stats", false, "Collect process stats (memory, CPU, FDs, threads) for every listener")
+	RootCmd.PersistentFlags().BoolVar(&showServices, "services", false, "Add a SERVICE column to tables and the watch view")
+	RootCmd.PersistentFlags().BoolVar(&dockerNames, "docker", false, "Name containers using the Docker Engine API ($DOCKER_HOST or /var/run/docker.sock)")
+	RootCmd.PersistentFlags().StringVar(&registryPath, "registry", "", "Service registry file mapping ports to names and owners (default: $PORTMAN_REGISTRY or ~/.config/portman/services)")
 
 	// Add subcommands
 	RootCmd.AddCommand(findCmd)
@@ -46,64 +74,73 @@ func init() {
 	RootCmd.AddCommand(waitCmd)
 	RootCmd.AddCommand(portCmd)
 	RootCmd.AddCommand(pidCmd)
+	RootCmd.AddCommand(watchCmd)
 }
 
-// parsePort validates and returns a port number
-func parsePort(s string) (int, error) {
-	port, err := strconv.Atoi(s)
-	if err != nil {
-		return 0, fmt.Errorf("invalid port: %s", s)
-	}
-	if port < 1 || port > 65535 {
-		return 0, fmt.Errorf("port must be between 1 and 65535")
+// scanDefaults returns the default scanner options with the global flags
+// that apply to every scan
+func scanDefaults() scanner.Options {
+	opts := scanner.DefaultOptions()
+	if dockerNames {
+		opts.DockerSocket = container.Socket()
 	}
-	return port, nil
+	return opts
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
-	opts := scanner.DefaultOptions()
+	out, err := parseOutput()
+	if err != nil {
+		return err
+	}
+
+	opts := out.scanOptions()
 	if tcpOnly {
 		opts.IncludeUDP = false
 	}
 	if udpOnly {
 		opts.IncludeTCP = false
 	}
+	if len(args) > 0 && !watchMode {
+		opts.FetchContext = true // For the detail view
+	}
 
 	s, err := scanner.New(opts)
 	if err != nil {
 		return err
 	}
 
-	// If port specified, show details (or watch single port)
-	if len(args) == 1 {
-		port, err := parsePort(args[0])
+	// If ports specified, show details (or watch them)
+	if len(args) > 0 {
+		targets, err := parseTargets(args)
 		if err != nil {
 			return err
 		}
-		if watchMode {
-			return ui.RunWatchPort(ui.WatchPortConfig{
-				Scanner:  s,
-				Port:     port,
-				Interval: watchInterval,
-			})
-		}
-		return showPortDetail(s, port)
+		return showPorts(s, targets, out)
 	}
 
 	// Otherwise list all
-	return listAllPorts(s)
+	return listAllPorts(s, out)
 }
 
-func listAllPorts(s scanner.Scanner) error {
+func listAllPorts(s scanner.Scanner, out outputSpec) error {
 	// Watch mode
 	if watchMode {
-		return ui.RunWatch(ui.WatchConfig{
+		cfg := ui.WatchConfig{
 			Scanner:  s,
 			Interval: watchInterval,
 			SortBy:   sortBy,
 			TCPOnly:  tcpOnly,
 			UDPOnly:  udpOnly,
-		})
+			LogLines: logLines,
+			LogFile:  logFile,
+			History:  historyWindow,
+			Columns:  out.watchColumns(),
+			Filter:   out.filter,
+		}
+		if streamEvents() {
+			return ui.StreamEvents(cfg, os.Stdout)
+		}
+		return ui.RunWatch(cfg)
 	}
 
 	listeners, err := s.ListListeners()
@@ -111,51 +148,121 @@ func listAllPorts(s scanner.Scanner) error {
 		return err
 	}
 
+	listeners = out.filter.Filter(listeners)
+
 	// Sort listeners
-	output.SortListeners(listeners, sortBy)
+	out.sort.Sort(listeners)
 
-	if jsonOutput {
-		formatter := output.NewJSONFormatter(true)
-		out, err := formatter.Format(listeners)
-		if err != nil {
-			return err
-		}
-		fmt.Println(out)
-	} else {
-		formatter := output.NewTableFormatter()
-		formatter.NoHeader = noHeader
-		fmt.Print(formatter.Format(listeners))
+	return out.printListeners(listeners)
+}
+
+// watchPort runs single-port watch mode, either as the live view or as an
+// event stream when output is JSON or not a terminal
+func watchPort(s scanner.Scanner, target portTarget) error {
+	if target.Host != "" {
+		s = hostScanner{Scanner: s, host: target.Host}
+	}
+
+	cfg := ui.WatchPortConfig{
+		Scanner:  s,
+		Port:     target.Port,
+		Interval: watchInterval,
+		History:  historyWindow,
 	}
+	if streamEvents() {
+		return ui.StreamPortEvents(cfg, os.Stdout)
+	}
+	return ui.RunWatchPort(cfg)
+}
 
+// loadRegistry loads the service registry. Only a path given with
+// --registry must exist.
+func loadRegistry() error {
+	path, required := registryPath, true
+	if path == "" {
+		path, required = services.DefaultRegistryPath(), false
+	}
+	if path == "" {
+		return nil
+	}
+	if err := services.LoadRegistry(path, required); err != nil {
+		return &Error{Code: CodeInvalidArgument, Message: err.Error(), Err: err}
+	}
 	return nil
 }
 
-func showPortDetail(s scanner.Scanner, port int) error {
-	listener, err := s.GetPort(port)
-	if err != nil {
-		return err
+// streamEvents reports whether watch mode should emit NDJSON events
+// instead of drawing the interactive view
+func streamEvents() bool {
+	return jsonRequested() || !ui.IsTerminal(os.Stdout)
+}
+
+// showPorts shows the detail of a single port, or a table of several,
+// leaving out listeners that don't match --filter or --project. With
+// --watch it watches them instead.
+func showPorts(s scanner.Scanner, targets []portTarget, out outputSpec) error {
+	if watchMode {
+		if out.filter != nil {
+			return invalidArgf("--filter and --project can't be combined with --watch on given ports")
+		}
+		if len(targets) == 1 {
+			return watchPort(s, targets[0])
+		}
+		return watchPorts(s, targets)
 	}
 
-	if listener == nil {
-		if jsonOutput {
-			fmt.Println("{}")
-		} else {
-			fmt.Printf("Port %d is not in use.\n", port)
+	if len(targets) == 1 {
+		listener, err := lookupTarget(s, targets[0])
+		if err != nil {
+			return err
 		}
-		return nil
+		if listener != nil && !out.filter.Match(*listener) {
+			switch {
+			case out.formatter() == nil:
+				fmt.Printf("Port %s is in use, but doesn't match %s.\n", targets[0], out.filter)
+				return nil
+			case out.format == "template":
+				fmt.Fprintf(os.Stderr, "Port %s is in use, but doesn't match %s.\n", targets[0], out.filter)
+				return nil
+			}
+			listener = nil
+		}
+		return out.printDetail(targets[0], listener)
 	}
 
-	if jsonOutput {
-		formatter := output.NewJSONFormatter(true)
-		out, err := formatter.FormatSingle(listener)
+	var listeners []model.Listener
+	var free []portTarget
+	for _, t := range targets {
+		l, err := lookupTarget(s, t)
 		if err != nil {
 			return err
 		}
-		fmt.Println(out)
-	} else {
-		formatter := output.NewTableFormatter()
-		fmt.Print(formatter.FormatDetail(listener))
+		if l == nil {
+			free = append(free, t)
+			continue
+		}
+		listeners = append(listeners, *l)
 	}
+	inUse := len(listeners)
+	listeners = out.filter.Filter(listeners)
 
+	if out.formatter() != nil {
+		return out.printListeners(listeners)
+	}
+
+	if inUse == 0 {
+		fmt.Printf("Ports %s are not in use.\n", joinTargets(targets))
+		return nil
+	}
+	if len(listeners) == 0 {
+		fmt.Printf("No listener on ports %s matches %s.\n", joinTargets(targets), out.filter)
+		return nil
+	}
+	if err := out.printListeners(listeners); err != nil {
+		return err
+	}
+	if len(free) > 0 {
+		fmt.Printf("\nNot in use: %s\n", joinTargets(free))
+	}
 	return nil
 }
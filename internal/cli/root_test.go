@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/scanner"
+)
+
+// listen opens a TCP listener on a free loopback port for the test
+func listen(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// freePort returns a loopback port nothing listens on
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+// skipUnsupported skips tests that scan when the platform has no scanner
+func skipUnsupported(t *testing.T) {
+	t.Helper()
+	if _, err := scanner.New(scanner.DefaultOptions()); errors.Is(err, scanner.ErrUnsupportedPlatform) || errors.Is(err, scanner.ErrNotImplemented) {
+		t.Skip(err)
+	}
+}
+
+func TestRootPortArgument(t *testing.T) {
+	skipUnsupported(t)
+	port := listen(t)
+
+	out, err := run(t, strconv.Itoa(port), "--json")
+	if err != nil {
+		t.Fatalf("portman %d: %v", port, err)
+	}
+
+	var result model.ScanResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("portman %d --json: %v\n%s", port, err, out)
+	}
+	if len(result.Listeners) != 1 {
+		t.Fatalf("portman %d --json listed %d listeners, want 1", port, len(result.Listeners))
+	}
+	if l := result.Listeners[0]; l.Port != port || l.PID != os.Getpid() {
+		t.Errorf("portman %d --json = port %d, pid %d; want port %d, pid %d", port, l.Port, l.PID, port, os.Getpid())
+	}
+}
+
+func TestRootPortArguments(t *testing.T) {
+	skipUnsupported(t)
+	used, free := listen(t), freePort(t)
+
+	out, err := run(t, strconv.Itoa(used), strconv.Itoa(free))
+	if err != nil {
+		t.Fatalf("portman %d %d: %v", used, free, err)
+	}
+	if !strings.Contains(out, strconv.Itoa(used)) || !strings.Contains(out, "Not in use: "+strconv.Itoa(free)) {
+		t.Errorf("portman %d %d printed:\n%s", used, free, out)
+	}
+}
+
+func TestRootInvalidPort(t *testing.T) {
+	skipUnsupported(t)
+	_, err := run(t, "notaport")
+	var e *Error
+	if !errors.As(err, &e) || e.Code != CodeInvalidArgument {
+		t.Errorf("portman notaport: error = %v, want an invalid argument error", err)
+	}
+}
+
+func TestReportWithoutPorts(t *testing.T) {
+	skipUnsupported(t)
+	port := listen(t)
+
+	out, err := run(t, "report")
+	if err != nil {
+		t.Fatalf("portman report: %v", err)
+	}
+	if !strings.Contains(out, "| "+strconv.Itoa(port)+" |") {
+		t.Errorf("portman report doesn't list port %d:\n%s", port, out)
+	}
+}
+
+func TestReportProject(t *testing.T) {
+	skipUnsupported(t)
+	port := listen(t)
+
+	// The test runs outside the project, so its listener is left out
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := run(t, "report", "--project", dir)
+	if err != nil {
+		t.Fatalf("portman report --project: %v", err)
+	}
+	if strings.Contains(out, "| "+strconv.Itoa(port)+" |") {
+		t.Errorf("portman report --project lists port %d, which isn't in the project:\n%s", port, out)
+	}
+}
+
+func TestReportRejectsOutputFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"report", "-o", "json"},
+		{"report", "--json"},
+		{"report", "--group-by", "process"},
+		{"report", "--template-file", "report.tmpl"},
+	} {
+		_, err := run(t, args...)
+		var e *Error
+		if !errors.As(err, &e) || e.Code != CodeInvalidArgument {
+			t.Errorf("portman %s: error = %v, want an invalid argument error", strings.Join(args, " "), err)
+		}
+	}
+}
+
+func TestRootPortFilter(t *testing.T) {
+	skipUnsupported(t)
+	used, free := listen(t), freePort(t)
+	port := strconv.Itoa(used)
+	mine := "pid==" + strconv.Itoa(os.Getpid())
+	other := "pid!=" + strconv.Itoa(os.Getpid())
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"match", []string{port, "--filter", mine}, strconv.Itoa(os.Getpid())},
+		{"no match", []string{port, "--filter", other}, "Port " + port + " is in use, but doesn't match " + other + "."},
+		{"several, no match", []string{port, strconv.Itoa(free), "--filter", other}, "No listener on ports " + port + ", " + strconv.Itoa(free) + " matches " + other + "."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := run(t, tt.args...)
+			if err != nil {
+				t.Fatalf("portman %s: %v", strings.Join(tt.args, " "), err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("portman %s printed:\n%s\nwant %q", strings.Join(tt.args, " "), out, tt.want)
+			}
+		})
+	}
+
+	out, err := run(t, port, "--filter", other, "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result model.ScanResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("portman %s --json: %v\n%s", port, err, out)
+	}
+	if len(result.Listeners) != 0 {
+		t.Errorf("portman %s --filter %q --json listed %d listeners, want none", port, other, len(result.Listeners))
+	}
+}
+
+func TestRootPortFilterWatch(t *testing.T) {
+	skipUnsupported(t)
+	_, err := run(t, "3000", "--watch", "--filter", "port==3000")
+	var e *Error
+	if !errors.As(err, &e) || e.Code != CodeInvalidArgument {
+		t.Errorf("portman 3000 --watch --filter: error = %v, want an invalid argument error", err)
+	}
+}
+
+func TestRootTemplateFreePort(t *testing.T) {
+	skipUnsupported(t)
+	port := strconv.Itoa(freePort(t))
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	out, err := run(t, port, "-o", "template={{.Port}}")
+	os.Stderr = stderr
+	w.Close()
+	msg, _ := io.ReadAll(r)
+
+	if err != nil || out != "" {
+		t.Errorf("portman %s -o template= = %q, %v, want no output", port, out, err)
+	}
+	if want := "Port " + port + " is not in use.\n"; string(msg) != want {
+		t.Errorf("portman %s -o template= wrote %q to stderr, want %q", port, msg, want)
+	}
+}
+
+func TestTargetScannerKeepsHosts(t *testing.T) {
+	skipUnsupported(t)
+	port, other := listen(t), listen(t)
+	s, err := scanner.New(scanner.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := targetScanner{Scanner: s, hosts: map[int]string{port: "192.0.2.1"}}
+
+	if l, err := ts.GetPort(port); err != nil || l != nil {
+		t.Errorf("GetPort(%d) on another host = %v, %v, want nil", port, l, err)
+	}
+	if l, err := ts.GetPort(other); err != nil || l == nil {
+		t.Errorf("GetPort(%d) without a host = %v, %v, want its listener", other, l, err)
+	}
+}
+
+func TestWatchSamePortTwice(t *testing.T) {
+	skipUnsupported(t)
+	for _, args := range [][]string{
+		{"watch", "3000", "localhost:3000"},
+		{"3000", "127.0.0.1:3000", "--watch"},
+	} {
+		_, err := run(t, args...)
+		var e *Error
+		if !errors.As(err, &e) || e.Code != CodeInvalidArgument {
+			t.Errorf("portman %s: error = %v, want an invalid argument error", strings.Join(args, " "), err)
+		}
+	}
+}
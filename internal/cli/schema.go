@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/schema"
+)
+
+func init() {
+	RootCmd.AddCommand(schemaCmd)
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [name]",
+	Short: "Print the JSON Schema of portman's JSON output",
+	Long: fmt.Sprintf(`Print the JSON Schema of portman's JSON output (schema version %d).
+
+Every command that prints JSON uses the scan-result envelope; errors use
+the error document (schema version %d), and --watch --json writes one
+event document per line (schema version %d). Available schemas: %s`, model.SchemaVersion, model.ErrorSchemaVersion, model.EventSchemaVersion, strings.Join(schema.Names(), ", ")),
+	Args: checkArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := "scan-result"
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		doc, ok := schema.Lookup(name)
+		if !ok {
+			return invalidArgf("unknown schema: %s (available: %s)", name, strings.Join(schema.Names(), ", "))
+		}
+
+		data, err := doc.Generate()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
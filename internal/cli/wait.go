@@ -28,14 +28,19 @@ func init() {
 }
 
 var waitCmd = &cobra.Command{
-	Use:   "wait <port>",
-	Short: "Wait until a port is available",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runWait,
+	Use:   "wait <port|range|host:port|service>...",
+	Short: "Wait until ports are available",
+	Long: `Wait until every given port is available, or free with --invert.
+
+Ports may be numbers, ranges (3000-3010), comma-separated lists (80,443),
+host:port forms (127.0.0.1:8080) or service names (postgresql). The
+timeout covers all of them together.`,
+	Args: checkArgs(cobra.MinimumNArgs(1)),
+	RunE: runWait,
 }
 
 func runWait(cmd *cobra.Command, args []string) error {
-	port, err := parsePort(args[0])
+	targets, err := parseTargets(args)
 	if err != nil {
 		return err
 	}
@@ -48,35 +53,46 @@ func runWait(cmd *cobra.Command, args []string) error {
 
 	if !waitQuiet {
 		if waitInvert {
-			fmt.Printf("Waiting for port %d to be free...\n", port)
+			fmt.Printf("Waiting for %s to be free...\n", describeTargets(targets))
 		} else {
-			fmt.Printf("Waiting for port %d...\n", port)
+			fmt.Printf("Waiting for %s...\n", describeTargets(targets))
 		}
 	}
 
-	result := wait.Wait(s, port, waitTimeout, waitCmdInterval, waitInvert)
+	// Wait for each port in turn within the shared deadline
+	start := time.Now()
+	for _, t := range targets {
+		var ts scanner.Scanner = s
+		if t.Host != "" {
+			ts = hostScanner{Scanner: s, host: t.Host}
+		}
+
+		remaining := waitTimeout - time.Since(start)
+		result := wait.Wait(ts, t.Port, remaining, waitCmdInterval, waitInvert)
 
-	if !result.Success {
-		if !waitQuiet {
-			fmt.Printf("Timeout: port %d ", port)
+		if !result.Success {
+			state := "is not available"
 			if waitInvert {
-				fmt.Println("is still in use.")
-			} else {
-				fmt.Println("is not available.")
+				state = "is still in use"
+			}
+			return &Error{
+				Code:    CodeTimeout,
+				Message: fmt.Sprintf("timeout after %s: port %s %s", waitTimeout, t, state),
+				Quiet:   waitQuiet,
 			}
 		}
-		os.Exit(1)
-	}
 
-	if !waitQuiet {
-		if waitInvert {
-			fmt.Printf("✓ Port %d is now free after %s\n", port, result.Elapsed.Round(time.Millisecond))
-		} else {
-			processInfo := ""
-			if result.ProcessName != "" {
-				processInfo = fmt.Sprintf(" (%s)", result.ProcessName)
+		if !waitQuiet {
+			elapsed := time.Since(start).Round(time.Millisecond)
+			if waitInvert {
+				fmt.Printf("✓ Port %s is now free after %s\n", t, elapsed)
+			} else {
+				processInfo := ""
+				if result.ProcessName != "" {
+					processInfo = fmt.Sprintf(" (%s)", result.ProcessName)
+				}
+				fmt.Printf("✓ Port %s is now open%s after %s\n", t, processInfo, elapsed)
 			}
-			fmt.Printf("✓ Port %d is now open%s after %s\n", port, processInfo, result.Elapsed.Round(time.Millisecond))
 		}
 	}
 
@@ -89,7 +105,15 @@ func runWait(cmd *cobra.Command, args []string) error {
 
 		if err := execCmd.Run(); err != nil {
 			if exitErr, ok := err.(*exec.ExitError); ok {
-				os.Exit(exitErr.ExitCode())
+				// Pass the command's exit code through; it has already
+				// reported its own failure
+				return &Error{
+					Code:    CodeCommandFailed,
+					Message: fmt.Sprintf("command exited with status %d", exitErr.ExitCode()),
+					Exit:    exitErr.ExitCode(),
+					Quiet:   true,
+					Err:     err,
+				}
 			}
 			return err
 		}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tasnimzotder/portman/internal/scanner"
+	"github.com/tasnimzotder/portman/internal/ui"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <port|range|host:port|service>...",
+	Short: "Watch several ports at once in a dashboard",
+	Args:  checkArgs(cobra.MinimumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets, err := parseTargets(args)
+		if err != nil {
+			return err
+		}
+
+		s, err := scanner.New(scanDefaults())
+		if err != nil {
+			return err
+		}
+
+		return watchPorts(s, targets)
+	},
+}
+
+// watchPorts runs the multi-port dashboard, or streams its events when
+// output is JSON or not a terminal. A port given as host:port only matches
+// listeners serving that host, as with watchPort.
+func watchPorts(s scanner.Scanner, targets []portTarget) error {
+	ports := make([]int, len(targets))
+	hosts := make(map[int]string)
+	given := make(map[int]portTarget)
+	for i, t := range targets {
+		if prev, ok := given[t.Port]; ok {
+			return invalidArgf("%s and %s are the same port; watch each port once", prev, t)
+		}
+		given[t.Port] = t
+		ports[i] = t.Port
+		if t.Host != "" {
+			hosts[t.Port] = t.Host
+		}
+	}
+
+	cfg := ui.DashboardConfig{
+		Scanner:  targetScanner{Scanner: s, hosts: hosts},
+		Ports:    ports,
+		Hosts:    hosts,
+		Interval: watchInterval,
+	}
+	if streamEvents() {
+		return ui.StreamDashboardEvents(cfg, os.Stdout)
+	}
+	return ui.RunDashboard(cfg)
+}
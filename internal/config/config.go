@@ -0,0 +1,229 @@
+// Package config loads portman's configuration: default flag values, port
+// aliases, and named port groups, from ~/.config/portman/config.yaml and a
+// project-local .portman.yaml.
+//
+//	defaults:
+//	  sort: user,-conns
+//	  interval: 2s
+//	  columns: [port, pid, user, rss]
+//	  stats: true
+//	aliases:
+//	  api: 8080
+//	  db: localhost:5432
+//	groups:
+//	  stack: [3000, api, db]
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LocalName is the file name of project-local configuration
+const LocalName = ".portman.yaml"
+
+// Setting is a configured value and where it came from
+type Setting struct {
+	Value  []string // One element for scalars
+	Source string   // File path
+	Line   int
+}
+
+// Where formats the source as "path:line"
+func (s Setting) Where() string {
+	return fmt.Sprintf("%s:%d", s.Source, s.Line)
+}
+
+// String joins list values with commas
+func (s Setting) String() string {
+	return strings.Join(s.Value, ",")
+}
+
+// Config is the merged configuration. Later files override earlier ones
+// key by key.
+type Config struct {
+	Defaults map[string]Setting // Flag name to default value
+	Aliases  map[string]Setting // Name to port argument
+	Groups   map[string]Setting // Name to port arguments
+	Files    []string           // Files that were loaded, in order
+	Warnings []error            // Settings that were ignored, with where they are
+}
+
+// Dir returns the portman config directory: $XDG_CONFIG_HOME/portman, or
+// ~/.config/portman
+func Dir() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "portman")
+}
+
+// GlobalPath returns the path of the user's config file
+func GlobalPath() string {
+	if dir := Dir(); dir != "" {
+		return filepath.Join(dir, "config.yaml")
+	}
+	return ""
+}
+
+// LocalPath returns the nearest .portman.yaml in the working directory or
+// one of its parents, or "" when there is none
+func LocalPath() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, LocalName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load reads the global config file and then the project-local one.
+// Missing files are skipped. Defaults in globalOnly are ignored with a
+// warning when the project-local file sets them.
+func Load() (*Config, error) {
+	c := &Config{
+		Defaults: make(map[string]Setting),
+		Aliases:  make(map[string]Setting),
+		Groups:   make(map[string]Setting),
+	}
+
+	if err := c.loadFile(GlobalPath(), false); err != nil {
+		return nil, err
+	}
+	if err := c.loadFile(LocalPath(), true); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// globalOnly lists the defaults that kill processes, run commands or write
+// files. A .portman.yaml comes with whatever directory it is found in, so
+// only the user's own config file may set them.
+var globalOnly = map[string]bool{
+	"kill.yes":    true,
+	"kill.force":  true,
+	"kill.signal": true,
+	"wait.exec":   true,
+	"log-file":    true,
+}
+
+// validName reports whether an alias or group name can't be read as a port
+// argument: it starts with a letter and holds letters, digits, "-", "_"
+// and ".", which rules out ports, ranges, lists and host:port forms
+func validName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+func (c *Config) loadFile(path string, local bool) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sections, err := parse(path, string(data))
+	if err != nil {
+		return err
+	}
+
+	for _, sec := range sections {
+		var dest map[string]Setting
+		switch sec.name {
+		case "defaults":
+			dest = c.Defaults
+		case "aliases":
+			dest = c.Aliases
+		case "groups":
+			dest = c.Groups
+		default:
+			return sec.errorf("unknown section %q (use defaults, aliases, or groups)", sec.name)
+		}
+		for _, e := range sec.entries {
+			switch sec.name {
+			case "defaults":
+				if local && globalOnly[e.key] {
+					c.Warnings = append(c.Warnings, e.errorf("%s can only be set in %s; ignoring it", e.key, GlobalPath()))
+					continue
+				}
+			case "aliases":
+				if !validName(e.key) {
+					return e.errorf("invalid alias name %q (start with a letter; names can't look like ports)", e.key)
+				}
+				if len(e.value) != 1 {
+					return e.errorf("alias %s must be a single port", e.key)
+				}
+			case "groups":
+				if !validName(e.key) {
+					return e.errorf("invalid group name %q (start with a letter)", e.key)
+				}
+			}
+			dest[e.key] = Setting{Value: e.value, Source: path, Line: e.line}
+		}
+	}
+
+	c.Files = append(c.Files, path)
+	return nil
+}
+
+// Alias returns the port argument an alias stands for
+func (c *Config) Alias(name string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	s, ok := c.Aliases[name]
+	if !ok {
+		return "", false
+	}
+	return s.Value[0], true
+}
+
+// Group returns the port arguments of a named group
+func (c *Config) Group(name string) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	s, ok := c.Groups[name]
+	return s.Value, ok
+}
+
+// Keys returns the keys of a section, sorted
+func Keys(m map[string]Setting) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setup writes the global and project-local config files, either of which
+// may be empty to leave it out, and makes the project the working directory
+func setup(t *testing.T, global, local string) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	project := t.TempDir()
+	sub := filepath.Join(project, "src", "api")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	globalPath := filepath.Join(home, "portman", "config.yaml")
+	localPath := filepath.Join(project, LocalName)
+	if global != "" {
+		if err := os.MkdirAll(filepath.Dir(globalPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(globalPath, []byte(global), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if local != "" {
+		if err := os.WriteFile(localPath, []byte(local), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return globalPath, localPath
+}
+
+func TestLoadMergeOrder(t *testing.T) {
+	globalPath, localPath := setup(t, `
+defaults:
+  sort: user
+  stats: true
+  kill.yes: true
+aliases:
+  api: 8080
+  db: 5432
+groups:
+  stack: [api, db]
+`, `
+defaults:
+  sort: -conns
+aliases:
+  api: 9090
+groups:
+  web: [api]
+`)
+
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Files, []string{globalPath, localPath}) {
+		t.Errorf("Files = %v, want global then local", c.Files)
+	}
+
+	tests := []struct {
+		section map[string]Setting
+		key     string
+		value   string
+		source  string
+	}{
+		{c.Defaults, "sort", "-conns", localPath},
+		{c.Defaults, "stats", "true", globalPath},
+		{c.Defaults, "kill.yes", "true", globalPath},
+		{c.Aliases, "api", "9090", localPath},
+		{c.Aliases, "db", "5432", globalPath},
+		{c.Groups, "stack", "api,db", globalPath},
+		{c.Groups, "web", "api", localPath},
+	}
+	for _, tt := range tests {
+		s, ok := tt.section[tt.key]
+		if !ok {
+			t.Errorf("%s is missing", tt.key)
+			continue
+		}
+		if s.String() != tt.value || s.Source != tt.source {
+			t.Errorf("%s = %q from %s, want %q from %s", tt.key, s.String(), s.Source, tt.value, tt.source)
+		}
+	}
+
+	if port, ok := c.Alias("api"); !ok || port != "9090" {
+		t.Errorf("Alias(api) = %q, %v", port, ok)
+	}
+	if ports, ok := c.Group("stack"); !ok || !reflect.DeepEqual(ports, []string{"api", "db"}) {
+		t.Errorf("Group(stack) = %v, %v", ports, ok)
+	}
+}
+
+func TestLoadMissingFiles(t *testing.T) {
+	setup(t, "", "")
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Files) != 0 || len(c.Defaults) != 0 {
+		t.Errorf("Load() without files = %+v", c)
+	}
+	var none *Config
+	if _, ok := none.Alias("api"); ok {
+		t.Error("a nil Config has no aliases")
+	}
+}
+
+func TestLoadGlobalOnly(t *testing.T) {
+	_, local := setup(t,
+		"defaults:\n  kill.signal: SIGINT\n",
+		"defaults:\n  kill.yes: true\n  kill.force: true\n  kill.signal: SIGKILL\n  wait.exec: rm -rf /\n  log-file: /etc/passwd\n  sort: port\n",
+	)
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v, want warnings only", err)
+	}
+
+	want := map[string]string{"kill.signal": "SIGINT", "sort": "port"}
+	if len(c.Defaults) != len(want) {
+		t.Errorf("Defaults = %v, want %v", c.Defaults, want)
+	}
+	for key, value := range want {
+		if got := c.Defaults[key].String(); got != value {
+			t.Errorf("Defaults[%s] = %q, want %q", key, got, value)
+		}
+	}
+
+	if len(c.Warnings) != 5 {
+		t.Fatalf("Warnings = %v, want one per global-only key", c.Warnings)
+	}
+	for i, key := range []string{"kill.yes", "kill.force", "kill.signal", "wait.exec", "log-file"} {
+		msg := c.Warnings[i].Error()
+		if !strings.HasPrefix(msg, fmt.Sprintf("%s:%d: %s can only be set in", local, i+2, key)) {
+			t.Errorf("Warnings[%d] = %q, want one for %s at %s:%d", i, msg, key, local, i+2)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		global string
+		local  string
+		want   string
+	}{
+		{"numeric alias", "aliases:\n  \"5432\": 3000\n", "", `invalid alias name "5432"`},
+		{"range alias", "aliases:\n  3000-3005: 8080\n", "", `invalid alias name "3000-3005"`},
+		{"host:port alias", "aliases:\n  \"localhost:80\": 8080\n", "", `invalid alias name "localhost:80"`},
+		{"list alias", "aliases:\n  \"80,443\": 8080\n", "", `invalid alias name "80,443"`},
+		{"numeric group", "groups:\n  \"80\": [8080]\n", "", `invalid group name "80"`},
+		{"list alias value", "aliases:\n  api: [80, 443]\n", "", "alias api must be a single port"},
+		{"unknown section", "flags:\n  sort: port\n", "", `unknown section "flags"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(t, tt.global, tt.local)
+			_, err := Load()
+			var e *Error
+			if !errors.As(err, &e) || !strings.Contains(e.Msg, tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"api", true},
+		{"web-2", true},
+		{"db_primary", true},
+		{"api.v2", true},
+		{"", false},
+		{"5432", false},
+		{"3000-3005", false},
+		{"2fa", false},
+		{"localhost:5432", false},
+		{"[::1]:80", false},
+		{"80,443", false},
+		{"@stack", false},
+		{"-api", false},
+		{"my api", false},
+	}
+	for _, tt := range tests {
+		if got := validName(tt.name); got != tt.want {
+			t.Errorf("validName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The config files use a small subset of YAML: top-level sections holding
+// mappings whose values are scalars, flow sequences ([a, b]), or block
+// sequences (- a). Comments and quoted scalars are supported.
+
+type section struct {
+	name    string
+	entries []entry
+	path    string
+	line    int
+}
+
+type entry struct {
+	key   string
+	value []string
+	path  string
+	line  int
+}
+
+// Error is a syntax error in a config file
+type Error struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+}
+
+func (s *section) errorf(format string, args ...any) error {
+	return &Error{Path: s.path, Line: s.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *entry) errorf(format string, args ...any) error {
+	return &Error{Path: e.path, Line: e.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func parse(path, text string) ([]section, error) {
+	var sections []section
+	var cur *section
+	var last *entry // Entry awaiting block sequence items
+	entryIndent := -1
+
+	errorAt := func(n int, format string, args ...any) error {
+		return &Error{Path: path, Line: n, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	for i, raw := range strings.Split(text, "\n") {
+		n := i + 1
+		line := strings.TrimRight(stripComment(raw), " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		content := strings.TrimLeft(line, " \t")
+		if strings.Contains(line[:len(line)-len(content)], "\t") {
+			return nil, errorAt(n, "indent with spaces, not tabs")
+		}
+		indent := len(line) - len(content)
+
+		// Top-level section
+		if indent == 0 {
+			key, value, ok := cutKey(content)
+			if !ok {
+				return nil, errorAt(n, "expected a section such as defaults:")
+			}
+			if value != "" && value != "{}" {
+				return nil, errorAt(n, "section %s must hold indented key: value lines", key)
+			}
+			sections = append(sections, section{name: key, path: path, line: n})
+			cur = &sections[len(sections)-1]
+			last, entryIndent = nil, -1
+			continue
+		}
+
+		if cur == nil {
+			return nil, errorAt(n, "unexpected indentation")
+		}
+
+		// Block sequence item under the previous entry
+		if item, ok := strings.CutPrefix(content, "- "); ok || content == "-" {
+			if last == nil || indent < entryIndent {
+				return nil, errorAt(n, "list item without a key")
+			}
+			v, err := unquote(strings.TrimSpace(item))
+			if err != nil {
+				return nil, errorAt(n, "%s", err)
+			}
+			last.value = append(last.value, v)
+			continue
+		}
+
+		if entryIndent == -1 {
+			entryIndent = indent
+		} else if indent != entryIndent {
+			return nil, errorAt(n, "inconsistent indentation")
+		}
+
+		key, value, ok := cutKey(content)
+		if !ok {
+			return nil, errorAt(n, "expected key: value")
+		}
+		key, err := unquote(key)
+		if err != nil {
+			return nil, errorAt(n, "%s", err)
+		}
+
+		e := entry{key: key, path: path, line: n}
+		switch {
+		case value == "":
+			// Values follow as a block sequence
+		case strings.HasPrefix(value, "["):
+			if !strings.HasSuffix(value, "]") {
+				return nil, errorAt(n, "unterminated list")
+			}
+			for _, item := range splitFlow(value[1 : len(value)-1]) {
+				v, err := unquote(item)
+				if err != nil {
+					return nil, errorAt(n, "%s", err)
+				}
+				e.value = append(e.value, v)
+			}
+		default:
+			v, err := unquote(value)
+			if err != nil {
+				return nil, errorAt(n, "%s", err)
+			}
+			e.value = []string{v}
+		}
+
+		cur.entries = append(cur.entries, e)
+		last = &cur.entries[len(cur.entries)-1]
+	}
+
+	for _, sec := range sections {
+		for _, e := range sec.entries {
+			if len(e.value) == 0 {
+				return nil, e.errorf("%s has no value", e.key)
+			}
+		}
+	}
+	return sections, nil
+}
+
+// cutKey splits "key: value" at the first colon followed by a space or the
+// end of the line, so values such as 127.0.0.1:8080 stay whole
+func cutKey(s string) (string, string, bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ') {
+			key := strings.TrimSpace(s[:i])
+			return key, strings.TrimSpace(s[i+1:]), key != ""
+		}
+	}
+	return "", "", false
+}
+
+// stripComment removes a # comment that isn't inside quotes
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// splitFlow splits the inside of a flow sequence at commas outside quotes
+func splitFlow(s string) []string {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" || len(items) > 0 {
+		items = append(items, last)
+	}
+	return items
+}
+
+// unquote strips matching single or double quotes from a scalar
+func unquote(s string) (string, error) {
+	if len(s) < 2 {
+		return s, nil
+	}
+	switch s[0] {
+	case '"':
+		if s[len(s)-1] != '"' {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		return strconv.Unquote(s)
+	case '\'':
+		if s[len(s)-1] != '\'' {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return s, nil
+}
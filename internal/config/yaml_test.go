@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	text := `# portman config
+defaults:
+  sort: user,-conns   # Trailing comment
+  columns: [port, pid, "user"]
+  log-file: '/tmp/it''s.log'
+aliases:
+  api: 8080
+  db: localhost:5432
+  "web": "#frontend"
+groups:
+  stack:
+    - 3000
+    - api
+  quoted: [3000, 'a,b']
+`
+	sections, err := parse("config.yaml", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type kv struct {
+		key   string
+		value []string
+		line  int
+	}
+	got := make(map[string][]kv)
+	var order []string
+	for _, sec := range sections {
+		order = append(order, sec.name)
+		for _, e := range sec.entries {
+			got[sec.name] = append(got[sec.name], kv{e.key, e.value, e.line})
+		}
+	}
+
+	want := map[string][]kv{
+		"defaults": {
+			{"sort", []string{"user,-conns"}, 3},
+			{"columns", []string{"port", "pid", "user"}, 4},
+			{"log-file", []string{"/tmp/it's.log"}, 5},
+		},
+		"aliases": {
+			{"api", []string{"8080"}, 7},
+			{"db", []string{"localhost:5432"}, 8},
+			{"web", []string{"#frontend"}, 9},
+		},
+		"groups": {
+			{"stack", []string{"3000", "api"}, 11},
+			{"quoted", []string{"3000", "a,b"}, 14},
+		},
+	}
+	if !reflect.DeepEqual(order, []string{"defaults", "aliases", "groups"}) {
+		t.Errorf("sections = %v", order)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parse() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		line int
+	}{
+		{"tab indent", "defaults:\n\tsort: port\n", 2},
+		{"no section", "  sort: port\n", 1},
+		{"scalar section", "defaults: port\n", 1},
+		{"missing colon", "defaults:\n  sort\n", 2},
+		{"inconsistent indent", "defaults:\n  sort: port\n    stats: true\n", 3},
+		{"unterminated list", "groups:\n  stack: [3000, 3001\n", 2},
+		{"unterminated string", "aliases:\n  api: \"8080\n", 2},
+		{"item without key", "groups:\n- 3000\n", 2},
+		{"no value", "groups:\n  stack:\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse("config.yaml", tt.text)
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("parse() error = %v, want a *config.Error", err)
+			}
+			if e.Path != "config.yaml" || e.Line != tt.line {
+				t.Errorf("parse() error at %s:%d, want config.yaml:%d (%s)", e.Path, e.Line, tt.line, e.Msg)
+			}
+		})
+	}
+}
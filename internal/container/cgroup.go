@@ -0,0 +1,91 @@
+// Package container recognizes containerized processes: it finds the
+// container and Kubernetes pod in a cgroup path, and looks up container
+// names and port mappings through the Docker Engine API.
+package container
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// idPattern matches the 64-hex-digit IDs that container runtimes put in
+// cgroup paths
+var idPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// podPattern matches a pod UID in a cgroup path. The systemd driver writes
+// it with underscores, e.g. kubepods-burstable-pod1a2b..._9f.slice.
+var podPattern = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+
+// scopeRuntimes maps the prefixes of systemd scope units to runtimes, e.g.
+// docker-<id>.scope
+var scopeRuntimes = []struct {
+	prefix  string
+	runtime string
+}{
+	{"docker-", "docker"},
+	{"libpod-conmon-", "podman"},
+	{"libpod-", "podman"},
+	{"cri-containerd-", "containerd"},
+	{"nerdctl-", "containerd"},
+	{"crio-conmon-", "cri-o"},
+	{"crio-", "cri-o"},
+}
+
+// parentRuntimes maps the directory above a bare ID to runtimes, for the
+// cgroupfs layouts such as /docker/<id>
+var parentRuntimes = map[string]string{
+	"docker":        "docker",
+	"libpod_parent": "podman",
+}
+
+// Parse finds the container in a cgroup path, as read by procinfo.Cgroup:
+//
+//	/system.slice/docker-<id>.scope                    Docker, systemd driver
+//	/docker/<id>                                       Docker, cgroupfs driver
+//	/machine.slice/libpod-<id>.scope                   podman
+//	/default/<id>                                      containerd (namespace/id)
+//	/kubepods/burstable/pod<uid>/<id>                  Kubernetes, cgroupfs driver
+//	/kubepods.slice/.../cri-containerd-<id>.scope      Kubernetes, systemd driver
+//
+// It returns nil for processes outside containers.
+func Parse(cgroup string) *model.Container {
+	segments := strings.Split(cgroup, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		seg := segments[i]
+		id := idPattern.FindString(seg)
+		if id == "" {
+			continue
+		}
+
+		c := &model.Container{ID: id}
+		if pod := podPattern.FindStringSubmatch(cgroup); pod != nil {
+			c.PodUID = strings.ReplaceAll(pod[1], "_", "-")
+		}
+
+		if seg != id {
+			for _, r := range scopeRuntimes {
+				if strings.HasPrefix(seg, r.prefix) {
+					c.Runtime = r.runtime
+					break
+				}
+			}
+			return c
+		}
+
+		// A bare ID: the runtime is named by the directory above, except
+		// under kubepods, where any runtime may have created it
+		parent := ""
+		if i > 0 {
+			parent = segments[i-1]
+		}
+		if r, ok := parentRuntimes[parent]; ok {
+			c.Runtime = r
+		} else if c.PodUID == "" {
+			c.Runtime = "containerd"
+		}
+		return c
+	}
+	return nil
+}
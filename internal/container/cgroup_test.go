@@ -0,0 +1,71 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+const (
+	id     = "4f6c1a3b2e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b"
+	podUID = "1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d"
+	podSD  = "1a2b3c4d_5e6f_7a8b_9c0d_1e2f3a4b5c6d" // As the systemd driver writes it
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		cgroup string
+		want   *model.Container
+	}{
+		// cgroup v1, cgroupfs driver
+		{"docker v1", "/docker/" + id, &model.Container{ID: id, Runtime: "docker"}},
+		{"podman v1", "/libpod_parent/libpod-" + id, &model.Container{ID: id, Runtime: "podman"}},
+		{"containerd v1", "/default/" + id, &model.Container{ID: id, Runtime: "containerd"}},
+		{"kubepods v1", "/kubepods/burstable/pod" + podUID + "/" + id, &model.Container{ID: id, PodUID: podUID}},
+		{"kubepods besteffort v1", "/kubepods/besteffort/pod" + podUID + "/" + id, &model.Container{ID: id, PodUID: podUID}},
+
+		// cgroup v2, systemd driver
+		{"docker v2", "/system.slice/docker-" + id + ".scope", &model.Container{ID: id, Runtime: "docker"}},
+		{"podman v2", "/machine.slice/libpod-" + id + ".scope", &model.Container{ID: id, Runtime: "podman"}},
+		{"podman conmon v2", "/machine.slice/libpod-conmon-" + id + ".scope", &model.Container{ID: id, Runtime: "podman"}},
+		{"podman rootless v2", "/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-" + id + ".scope/container", &model.Container{ID: id, Runtime: "podman"}},
+		{"nerdctl v2", "/system.slice/nerdctl-" + id + ".scope", &model.Container{ID: id, Runtime: "containerd"}},
+		{"containerd v2", "/default/" + id, &model.Container{ID: id, Runtime: "containerd"}},
+		{
+			"kubepods containerd v2",
+			"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + podSD + ".slice/cri-containerd-" + id + ".scope",
+			&model.Container{ID: id, Runtime: "containerd", PodUID: podUID},
+		},
+		{
+			"kubepods cri-o v2",
+			"/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod" + podSD + ".slice/crio-" + id + ".scope",
+			&model.Container{ID: id, Runtime: "cri-o", PodUID: podUID},
+		},
+		{
+			"kubepods cri-o conmon v2",
+			"/kubepods.slice/kubepods-pod" + podSD + ".slice/crio-conmon-" + id + ".scope",
+			&model.Container{ID: id, Runtime: "cri-o", PodUID: podUID},
+		},
+
+		// Not in a container
+		{"empty", "", nil},
+		{"root", "/", nil},
+		{"user session", "/user.slice/user-1000.slice/session-2.scope", nil},
+		{"system service", "/system.slice/docker.service", nil},
+		{"short hex", "/docker/4f6c1a3b2e9d", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.cgroup)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil:
+				t.Fatalf("Parse(%q) = %+v, want %+v", tt.cgroup, got, tt.want)
+			case *got != *tt.want:
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.cgroup, *got, *tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultSocket is where the Docker daemon listens unless $DOCKER_HOST says
+// otherwise
+const DefaultSocket = "/var/run/docker.sock"
+
+// Timeout bounds each Docker Engine API request, so a hung daemon can't
+// stall a scan
+var Timeout = 2 * time.Second
+
+// idleTimeout closes the kept-alive API connection between scans that are
+// far apart
+const idleTimeout = 30 * time.Second
+
+// Socket returns the path of the Docker Engine API socket: $DOCKER_HOST if
+// it is a unix:// address, else DefaultSocket
+func Socket() string {
+	if host, ok := strings.CutPrefix(os.Getenv("DOCKER_HOST"), "unix://"); ok && host != "" {
+		return host
+	}
+	return DefaultSocket
+}
+
+// Info is what the Docker Engine API reports about a running container
+type Info struct {
+	ID    string
+	Name  string
+	Image string
+	IPs   []string      // Addresses on the container's networks
+	Ports []PortMapping // Published ports
+}
+
+// PortMapping is a container port published on the host
+type PortMapping struct {
+	HostIP        string
+	HostPort      int
+	ContainerPort int
+	Protocol      string // "tcp" or "udp"
+}
+
+// Client talks to the Docker Engine API over a unix socket. podman's
+// Docker-compatible socket works too. A client keeps its connection open
+// between requests, so create one and reuse it.
+type Client struct {
+	Socket string
+	http   *http.Client
+}
+
+// NewClient returns a client for the API socket at path
+func NewClient(path string) *Client {
+	dialer := net.Dialer{Timeout: Timeout}
+	return &Client{
+		Socket: path,
+		http: &http.Client{
+			Timeout: Timeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", path)
+				},
+				MaxIdleConns:    1,
+				IdleConnTimeout: idleTimeout,
+			},
+		},
+	}
+}
+
+// containerSummary is an entry of GET /containers/json
+type containerSummary struct {
+	ID    string   `json:"Id"`
+	Names []string `json:"Names"`
+	Image string   `json:"Image"`
+	Ports []struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// Containers lists the running containers
+func (c *Client) Containers() ([]Info, error) {
+	var summaries []containerSummary
+	if err := c.get("/containers/json", &summaries); err != nil {
+		return nil, err
+	}
+
+	infos := make([]Info, 0, len(summaries))
+	for _, s := range summaries {
+		info := Info{ID: s.ID, Image: s.Image}
+		if len(s.Names) > 0 {
+			info.Name = strings.TrimPrefix(s.Names[0], "/")
+		}
+		for _, n := range s.NetworkSettings.Networks {
+			if n.IPAddress != "" {
+				info.IPs = append(info.IPs, n.IPAddress)
+			}
+		}
+		for _, p := range s.Ports {
+			if p.PublicPort == 0 {
+				continue // Exposed but not published
+			}
+			info.Ports = append(info.Ports, PortMapping{HostIP: p.IP, HostPort: p.PublicPort, ContainerPort: p.PrivatePort, Protocol: p.Type})
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// PID returns the host PID of a container's main process
+func (c *Client) PID(id string) (int, error) {
+	var inspect struct {
+		State struct {
+			Pid int `json:"Pid"`
+		} `json:"State"`
+	}
+	if err := c.get("/containers/"+id+"/json", &inspect); err != nil {
+		return 0, err
+	}
+	return inspect.State.Pid, nil
+}
+
+// get decodes the JSON response to an API request
+func (c *Client) get(path string, v any) error {
+	// The host is ignored, as every request goes to the socket
+	resp, err := c.http.Get("http://docker" + path)
+	if err != nil {
+		return fmt.Errorf("docker API at %s: %w", c.Socket, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("docker API at %s: GET %s: %s", c.Socket, path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("docker API at %s: GET %s: %w", c.Socket, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package container
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveDocker serves handler on a unix socket, as the Docker daemon does,
+// and returns the socket path
+func serveDocker(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	// t.TempDir can exceed the 108-byte limit on socket paths
+	dir, err := os.MkdirTemp("", "docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "docker.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Listener = ln
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return path
+}
+
+const containersJSON = `[
+  {
+    "Id": "4f6c1a3b2e9d",
+    "Names": ["/web"],
+    "Image": "nginx:1.27",
+    "Ports": [
+      {"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"},
+      {"PrivatePort": 443, "Type": "tcp"}
+    ],
+    "NetworkSettings": {"Networks": {"bridge": {"IPAddress": "172.17.0.2"}}}
+  },
+  {
+    "Id": "9e8d7c6b5a4f",
+    "Names": ["/worker"],
+    "Image": "worker:latest",
+    "Ports": [],
+    "NetworkSettings": {"Networks": {"none": {"IPAddress": ""}}}
+  }
+]`
+
+func TestClientContainers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(containersJSON))
+	})
+	client := NewClient(serveDocker(t, mux))
+
+	got, err := client.Containers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Info{
+		{
+			ID:    "4f6c1a3b2e9d",
+			Name:  "web",
+			Image: "nginx:1.27",
+			IPs:   []string{"172.17.0.2"},
+			Ports: []PortMapping{{HostIP: "0.0.0.0", HostPort: 8080, ContainerPort: 80, Protocol: "tcp"}},
+		},
+		{ID: "9e8d7c6b5a4f", Name: "worker", Image: "worker:latest"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Containers() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClientPID(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/4f6c1a3b2e9d/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Id": "4f6c1a3b2e9d", "State": {"Running": true, "Pid": 4242}}`))
+	})
+	client := NewClient(serveDocker(t, mux))
+
+	pid, err := client.PID("4f6c1a3b2e9d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != 4242 {
+		t.Errorf("PID() = %d, want 4242", pid)
+	}
+
+	// The mux answers 404 for other containers
+	if _, err := client.PID("missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("PID(missing) error = %v, want a 404", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	t.Run("daemon down", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "docker.sock")
+		_, err := NewClient(path).Containers()
+		if err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("Containers() error = %v, want one naming %s", err, path)
+		}
+	})
+
+	t.Run("malformed response", func(t *testing.T) {
+		client := NewClient(serveDocker(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"message": "not a list"}`))
+		})))
+		if _, err := client.Containers(); err == nil {
+			t.Error("Containers() succeeded on a malformed response")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		defer func(d time.Duration) { Timeout = d }(Timeout)
+		Timeout = 50 * time.Millisecond
+
+		release := make(chan struct{})
+		path := serveDocker(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer close(release)
+
+		start := time.Now()
+		_, err := NewClient(path).Containers()
+		if err == nil {
+			t.Fatal("Containers() succeeded against a hung daemon")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Containers() took %v, want it bounded by Timeout", elapsed)
+		}
+	})
+}
+
+func TestSocket(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"", DefaultSocket},
+		{"unix:///run/user/1000/docker.sock", "/run/user/1000/docker.sock"},
+		{"tcp://127.0.0.1:2375", DefaultSocket},
+		{"unix://", DefaultSocket},
+	}
+	for _, tt := range tests {
+		t.Setenv("DOCKER_HOST", tt.host)
+		if got := Socket(); got != tt.want {
+			t.Errorf("Socket() with DOCKER_HOST=%q = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
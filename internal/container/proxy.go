@@ -0,0 +1,119 @@
+package container
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// ProxyName is the command Docker runs for each published port when its
+// userland proxy is enabled
+const ProxyName = "docker-proxy"
+
+// IsProxy reports whether a process is a docker-proxy
+func IsProxy(p *model.Process) bool {
+	if p == nil {
+		return false
+	}
+	if p.Name == ProxyName {
+		return true
+	}
+	return len(p.Cmdline) > 0 && filepath.Base(p.Cmdline[0]) == ProxyName
+}
+
+// ProxyForward reads where a docker-proxy forwards to from its command line:
+//
+//	docker-proxy -proto tcp -host-ip 0.0.0.0 -host-port 8080 -container-ip 172.17.0.2 -container-port 80
+//
+// It returns nil when the arguments don't name a container port.
+func ProxyForward(cmdline []string) *model.Forward {
+	args := make(map[string]string)
+	for i := 1; i < len(cmdline); i++ {
+		name, ok := strings.CutPrefix(cmdline[i], "-")
+		if !ok {
+			continue
+		}
+		name = strings.TrimPrefix(name, "-") // Go flags accept -name and --name
+		if k, v, ok := strings.Cut(name, "="); ok {
+			args[k] = v
+		} else if i+1 < len(cmdline) && !strings.HasPrefix(cmdline[i+1], "-") {
+			args[name] = cmdline[i+1]
+			i++
+		} else {
+			args[name] = "true" // A boolean flag such as -use-listen-fd
+		}
+	}
+
+	port, err := strconv.Atoi(args["container-port"])
+	if err != nil || port <= 0 {
+		return nil
+	}
+	return &model.Forward{Via: ProxyName, ContainerIP: args["container-ip"], ContainerPort: port}
+}
+
+// Match finds the container a forward leads to, by its address on the
+// container networks, or else by the host port it publishes
+func Match(infos []Info, f *model.Forward, hostPort int, proto string) (Info, bool) {
+	if f.ContainerIP != "" {
+		for _, info := range infos {
+			for _, ip := range info.IPs {
+				if ip == f.ContainerIP {
+					return info, true
+				}
+			}
+		}
+	}
+	info, _, ok := Published(infos, hostPort, proto)
+	return info, ok
+}
+
+// Published finds the container publishing a host port, with the mapping
+func Published(infos []Info, hostPort int, proto string) (Info, PortMapping, bool) {
+	for _, info := range infos {
+		for _, p := range info.Ports {
+			if p.HostPort == hostPort && p.Protocol == proto {
+				return info, p, true
+			}
+		}
+	}
+	return Info{}, PortMapping{}, false
+}
+
+// PublishedAt finds the container publishing a host port on an address, for
+// a listener that forwards it without docker-proxy's arguments, such as
+// Docker Desktop's com.docker.backend or rootless Docker's rootlessport. A
+// mapping serves the address when either binds all interfaces.
+func PublishedAt(infos []Info, addr string, hostPort int, proto string) (Info, PortMapping, bool) {
+	for _, info := range infos {
+		for _, p := range info.Ports {
+			if p.HostPort == hostPort && p.Protocol == proto && sameHost(p.HostIP, addr) {
+				return info, p, true
+			}
+		}
+	}
+	return Info{}, PortMapping{}, false
+}
+
+// sameHost reports whether two bind addresses can be the same socket
+func sameHost(a, b string) bool {
+	if a == b || a == "" || b == "" || a == "*" || b == "*" {
+		return true
+	}
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return false
+	}
+	return ipA.IsUnspecified() || ipB.IsUnspecified() || ipA.Equal(ipB)
+}
+
+// ForwarderName names the process holding a published port, from its
+// executable when known, as lsof truncates command names
+func ForwarderName(p *model.Process) string {
+	if len(p.Cmdline) > 0 {
+		return filepath.Base(p.Cmdline[0])
+	}
+	return p.Name
+}
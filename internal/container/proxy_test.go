@@ -0,0 +1,162 @@
+package container
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+func TestIsProxy(t *testing.T) {
+	tests := []struct {
+		p    *model.Process
+		want bool
+	}{
+		{nil, false},
+		{&model.Process{Name: "docker-proxy"}, true},
+		{&model.Process{Name: "docker-pr", Cmdline: []string{"/usr/bin/docker-proxy", "-proto", "tcp"}}, true},
+		{&model.Process{Name: "nginx", Cmdline: []string{"nginx", "-g", "daemon off;"}}, false},
+		{&model.Process{Name: "com.docke", Cmdline: []string{"/Applications/Docker.app/Contents/MacOS/com.docker.backend"}}, false},
+	}
+	for _, tt := range tests {
+		if got := IsProxy(tt.p); got != tt.want {
+			t.Errorf("IsProxy(%+v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestProxyForward(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmdline []string
+		want    *model.Forward
+	}{
+		{
+			"single dash",
+			[]string{"/usr/bin/docker-proxy", "-proto", "tcp", "-host-ip", "0.0.0.0", "-host-port", "8080", "-container-ip", "172.17.0.2", "-container-port", "80"},
+			&model.Forward{Via: "docker-proxy", ContainerIP: "172.17.0.2", ContainerPort: 80},
+		},
+		{
+			"double dash and equals",
+			[]string{"docker-proxy", "--proto=udp", "--host-port=5353", "--container-ip=172.18.0.4", "--container-port=53"},
+			&model.Forward{Via: "docker-proxy", ContainerIP: "172.18.0.4", ContainerPort: 53},
+		},
+		{
+			"boolean flag before a value",
+			[]string{"docker-proxy", "-use-listen-fd", "-container-ip", "172.17.0.3", "-container-port", "443", "-host-port", "8443"},
+			&model.Forward{Via: "docker-proxy", ContainerIP: "172.17.0.3", ContainerPort: 443},
+		},
+		{
+			"IPv6 container",
+			[]string{"docker-proxy", "-container-ip", "fd00::2", "-container-port", "80"},
+			&model.Forward{Via: "docker-proxy", ContainerIP: "fd00::2", ContainerPort: 80},
+		},
+		{"no container port", []string{"docker-proxy", "-container-ip", "172.17.0.2"}, nil},
+		{"bad container port", []string{"docker-proxy", "-container-port", "http"}, nil},
+		{"trailing flag", []string{"docker-proxy", "-container-port"}, nil},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProxyForward(tt.cmdline); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProxyForward() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fixture is what the Docker Engine API reports for two containers: web,
+// published on all interfaces, and dns, published on loopback over UDP
+var fixture = []Info{
+	{
+		ID:    "4f6c1a3b2e9d",
+		Name:  "web",
+		IPs:   []string{"172.17.0.2"},
+		Ports: []PortMapping{{HostIP: "0.0.0.0", HostPort: 8080, ContainerPort: 80, Protocol: "tcp"}, {HostIP: "::", HostPort: 8080, ContainerPort: 80, Protocol: "tcp"}},
+	},
+	{
+		ID:    "9e8d7c6b5a4f",
+		Name:  "dns",
+		IPs:   []string{"172.18.0.4"},
+		Ports: []PortMapping{{HostIP: "127.0.0.1", HostPort: 5353, ContainerPort: 53, Protocol: "udp"}},
+	},
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        *model.Forward
+		hostPort int
+		proto    string
+		want     string
+	}{
+		{"by IP", &model.Forward{ContainerIP: "172.18.0.4"}, 9999, "tcp", "dns"},
+		{"IP wins over port", &model.Forward{ContainerIP: "172.18.0.4"}, 8080, "tcp", "dns"},
+		{"by port", &model.Forward{ContainerIP: "10.0.0.9"}, 8080, "tcp", "web"},
+		{"by port without IP", &model.Forward{}, 5353, "udp", "dns"},
+		{"wrong protocol", &model.Forward{}, 5353, "tcp", ""},
+		{"no match", &model.Forward{ContainerIP: "10.0.0.9"}, 9999, "tcp", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, ok := Match(fixture, tt.f, tt.hostPort, tt.proto)
+			if ok != (tt.want != "") || info.Name != tt.want {
+				t.Errorf("Match() = %q, %v; want %q", info.Name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublished(t *testing.T) {
+	info, mapping, ok := Published(fixture, 5353, "udp")
+	if !ok || info.Name != "dns" || mapping.ContainerPort != 53 || mapping.HostIP != "127.0.0.1" {
+		t.Errorf("Published(5353/udp) = %q, %+v, %v", info.Name, mapping, ok)
+	}
+	if _, _, ok := Published(fixture, 80, "tcp"); ok {
+		t.Error("Published(80/tcp) matched a container port, not a host port")
+	}
+	if _, _, ok := Published(nil, 8080, "tcp"); ok {
+		t.Error("Published() matched without containers")
+	}
+}
+
+func TestPublishedAt(t *testing.T) {
+	tests := []struct {
+		addr     string
+		hostPort int
+		proto    string
+		want     string
+	}{
+		{"0.0.0.0", 8080, "tcp", "web"},
+		{"::", 8080, "tcp", "web"},
+		{"*", 8080, "tcp", "web"},
+		{"192.168.1.5", 8080, "tcp", "web"}, // Published on all interfaces
+		{"127.0.0.1", 5353, "udp", "dns"},
+		{"0.0.0.0", 5353, "udp", "dns"},  // A wildcard bind serves loopback
+		{"192.168.1.5", 5353, "udp", ""}, // Published on loopback only
+		{"127.0.0.1", 5353, "tcp", ""},
+		{"127.0.0.1", 3000, "tcp", ""},
+	}
+	for _, tt := range tests {
+		info, _, ok := PublishedAt(fixture, tt.addr, tt.hostPort, tt.proto)
+		if ok != (tt.want != "") || info.Name != tt.want {
+			t.Errorf("PublishedAt(%s, %d/%s) = %q, %v; want %q", tt.addr, tt.hostPort, tt.proto, info.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestForwarderName(t *testing.T) {
+	tests := []struct {
+		p    *model.Process
+		want string
+	}{
+		{&model.Process{Name: "com.docke", Cmdline: []string{"/Applications/Docker.app/Contents/MacOS/com.docker.backend", "run"}}, "com.docker.backend"},
+		{&model.Process{Name: "rootlessport", Cmdline: []string{"rootlessport"}}, "rootlessport"},
+		{&model.Process{Name: "vpnkit"}, "vpnkit"},
+	}
+	for _, tt := range tests {
+		if got := ForwarderName(tt.p); got != tt.want {
+			t.Errorf("ForwarderName(%+v) = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package filter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+type kind int
+
+const (
+	kindNumber kind = iota
+	kindString
+)
+
+func (k kind) String() string {
+	if k == kindNumber {
+		return "number"
+	}
+	return "string"
+}
+
+// unit says how number literals compared with a field are read
+type unit int
+
+const (
+	unitNone     unit = iota
+	unitDuration      // Seconds, or durations like 90s, 1h30m, 2d
+	unitBytes         // Bytes, or sizes like 512K, 100MB, 1GiB
+	unitPercent       // Percentages like 50 or 50%
+)
+
+// field is a listener value that expressions can refer to
+type field struct {
+	name   string
+	kind   kind
+	unit   unit
+	stats  bool // Reads process stats, which the scanner only collects on request
+	ctx    bool // Reads the launch context of the process, also collected on request
+	number func(l model.Listener) float64
+	text   func(l model.Listener) string
+}
+
+var fields = map[string]*field{
+	"port":  {name: "port", kind: kindNumber, number: func(l model.Listener) float64 { return float64(l.Port) }},
+	"proto": {name: "proto", kind: kindString, text: func(l model.Listener) string { return l.Protocol }},
+	"addr":  {name: "addr", kind: kindString, text: func(l model.Listener) string { return l.Address }},
+	"service": {name: "service", kind: kindString, text: func(l model.Listener) string {
+		if l.Service == nil {
+			return ""
+		}
+		return l.Service.Name
+	}},
+	"owner": {name: "owner", kind: kindString, text: func(l model.Listener) string {
+		if l.Service == nil {
+			return ""
+		}
+		return l.Service.Owner
+	}},
+	"pid":     {name: "pid", kind: kindNumber, number: func(l model.Listener) float64 { return float64(l.PID) }},
+	"conns":   {name: "conns", kind: kindNumber, number: func(l model.Listener) float64 { return float64(l.ConnectionCount) }},
+	"name":    {name: "name", kind: kindString, text: processText(func(p *model.Process) string { return p.Name })},
+	"cmd":     {name: "cmd", kind: kindString, text: processText(func(p *model.Process) string { return p.Command })},
+	"cmdline": {name: "cmdline", kind: kindString, text: processText(func(p *model.Process) string { return strings.Join(p.Cmdline, " ") })},
+	"user":    {name: "user", kind: kindString, text: processText(func(p *model.Process) string { return p.User })},
+	"project": {name: "project", kind: kindString, ctx: true, text: processText(func(p *model.Process) string {
+		if p.Project == nil {
+			return ""
+		}
+		return p.Project.Name
+	})},
+	"container": {name: "container", kind: kindString, text: func(l model.Listener) string {
+		if l.Process != nil && l.Process.Container != nil {
+			return l.Process.Container.Label()
+		}
+		if l.Forward != nil {
+			return l.Forward.Container.Label()
+		}
+		return ""
+	}},
+	"pod": {name: "pod", kind: kindString, text: processText(func(p *model.Process) string {
+		if p.Container == nil {
+			return ""
+		}
+		return p.Container.PodUID
+	})},
+	"cgroup":  {name: "cgroup", kind: kindString, text: processText(func(p *model.Process) string { return p.Cgroup })},
+	"uid":     {name: "uid", kind: kindNumber, number: processNumber(func(p *model.Process) float64 { return float64(p.UID) })},
+	"uptime":  {name: "uptime", kind: kindNumber, unit: unitDuration, number: processNumber(func(p *model.Process) float64 { return float64(p.UptimeSeconds) })},
+	"rss":     {name: "rss", kind: kindNumber, unit: unitBytes, stats: true, number: statsNumber(func(s *model.ProcessStats) float64 { return float64(s.MemoryRSS) })},
+	"cpu":     {name: "cpu", kind: kindNumber, unit: unitPercent, stats: true, number: statsNumber(func(s *model.ProcessStats) float64 { return s.CPUPercent })},
+	"fds":     {name: "fds", kind: kindNumber, stats: true, number: statsNumber(func(s *model.ProcessStats) float64 { return float64(s.FDCount) })},
+	"threads": {name: "threads", kind: kindNumber, stats: true, number: statsNumber(func(s *model.ProcessStats) float64 { return float64(s.ThreadCount) })},
+}
+
+// fieldAliases maps alternative names to field names
+var fieldAliases = map[string]string{
+	"protocol":    "proto",
+	"address":     "addr",
+	"svc":         "service",
+	"connections": "conns",
+	"process":     "name",
+	"command":     "cmd",
+	"args":        "cmdline",
+	"mem":         "rss",
+	"memory":      "rss",
+}
+
+func lookupField(name string) (*field, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := fieldAliases[name]; ok {
+		name = alias
+	}
+	f, ok := fields[name]
+	return f, ok
+}
+
+// FieldNames returns the names of all fields, sorted
+func FieldNames() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func processText(get func(p *model.Process) string) func(model.Listener) string {
+	return func(l model.Listener) string {
+		if l.Process == nil {
+			return ""
+		}
+		return get(l.Process)
+	}
+}
+
+func processNumber(get func(p *model.Process) float64) func(model.Listener) float64 {
+	return func(l model.Listener) float64 {
+		if l.Process == nil {
+			return 0
+		}
+		return get(l.Process)
+	}
+}
+
+func statsNumber(get func(s *model.ProcessStats) float64) func(model.Listener) float64 {
+	return func(l model.Listener) float64 {
+		if l.Stats == nil {
+			return 0
+		}
+		return get(l.Stats)
+	}
+}
@@ -0,0 +1,486 @@
+// Package filter implements the --filter expression language, e.g.
+//
+//	port>=3000 && user=="me" && conns>0 && cmd=~"node|deno" && addr!="127.0.0.1"
+//
+// Expressions compare listener fields with literals using == != < <= > >=,
+// match strings against regular expressions with =~ and !~, and combine
+// comparisons with &&, ||, ! and parentheses.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// Error is a syntax or type error in an expression, pointing at the column
+// (1-based, in characters) where it was found
+type Error struct {
+	Expr   string
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid filter at column %d: %s\n  %s\n  %s^",
+		e.Column, e.Msg, e.Expr, strings.Repeat(" ", e.Column-1))
+}
+
+// Expr is a parsed filter expression. A nil Expr matches everything.
+type Expr struct {
+	text  string
+	root  node
+	stats bool
+	ctx   bool
+}
+
+// Parse parses a filter expression
+func Parse(text string) (*Expr, error) {
+	toks, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{text: text, toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "expected && or || before %s", t.describe())
+	}
+
+	return &Expr{text: text, root: root, stats: p.stats, ctx: p.ctx}, nil
+}
+
+// Match reports whether a listener satisfies the expression
+func (e *Expr) Match(l model.Listener) bool {
+	if e == nil {
+		return true
+	}
+	return e.root.eval(l)
+}
+
+// Filter returns the listeners that satisfy the expression
+func (e *Expr) Filter(listeners []model.Listener) []model.Listener {
+	if e == nil {
+		return listeners
+	}
+	matches := make([]model.Listener, 0, len(listeners))
+	for _, l := range listeners {
+		if e.Match(l) {
+			matches = append(matches, l)
+		}
+	}
+	return matches
+}
+
+// NeedsStats reports whether the expression reads process stats
+func (e *Expr) NeedsStats() bool {
+	return e != nil && e.stats
+}
+
+// NeedsContext reports whether the expression reads the launch context of
+// processes
+func (e *Expr) NeedsContext() bool {
+	return e != nil && e.ctx
+}
+
+// And returns an expression that also requires match, for flags such as
+// --project that narrow a listing like a filter. text describes the
+// condition, and ctx says whether it reads launch context.
+func (e *Expr) And(text string, match func(l model.Listener) bool, ctx bool) *Expr {
+	cond := funcNode(match)
+	if e == nil {
+		return &Expr{text: text, root: cond, ctx: ctx}
+	}
+	return &Expr{text: e.text + " && " + text, root: andNode{e.root, cond}, stats: e.stats, ctx: e.ctx || ctx}
+}
+
+func (e *Expr) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.text
+}
+
+// Tokens
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokKind
+	text string // Word, unquoted string, or operator
+	col  int
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// isWordRune reports whether r can appear in a field name or bare value
+// such as 127.0.0.1, tcp, 1h30m, or 512MB
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.:-/%*", r)
+}
+
+func lex(text string) ([]token, error) {
+	runes := []rune(text)
+	var toks []token
+
+	errorAt := func(i int, format string, args ...any) error {
+		return &Error{Expr: text, Column: i + 1, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					switch runes[i] {
+					case 'n':
+						sb.WriteRune('\n')
+					case 't':
+						sb.WriteRune('\t')
+					default:
+						// Keep escapes regexps need, like \d and \.
+						if runes[i] != r && runes[i] != '\\' {
+							sb.WriteRune('\\')
+						}
+						sb.WriteRune(runes[i])
+					}
+					continue
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errorAt(start, "unterminated string")
+			}
+			i++
+			toks = append(toks, token{kind: tokString, text: sb.String(), col: start + 1})
+		case r == '&' || r == '|':
+			if next != r {
+				return nil, errorAt(i, "expected %c%c", r, r)
+			}
+			kind := tokAnd
+			if r == '|' {
+				kind = tokOr
+			}
+			toks = append(toks, token{kind: kind, text: string([]rune{r, r}), col: i + 1})
+			i += 2
+		case r == '(':
+			toks = append(toks, token{kind: tokLParen, text: "(", col: i + 1})
+			i++
+		case r == ')':
+			toks = append(toks, token{kind: tokRParen, text: ")", col: i + 1})
+			i++
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if next == '=' || (next == '~' && (r == '=' || r == '!')) {
+				op += string(next)
+			}
+			switch op {
+			case "=":
+				return nil, errorAt(i, "use == to compare")
+			case "!":
+				toks = append(toks, token{kind: tokNot, text: op, col: i + 1})
+			default:
+				toks = append(toks, token{kind: tokOp, text: op, col: i + 1})
+			}
+			i += len(op)
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokWord, text: string(runes[start:i]), col: start + 1})
+		default:
+			return nil, errorAt(i, "unexpected character %q", r)
+		}
+	}
+
+	return append(toks, token{kind: tokEOF, col: len(runes) + 1}), nil
+}
+
+// Parser
+
+type parser struct {
+	text  string
+	toks  []token
+	pos   int
+	stats bool
+	ctx   bool
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return &Error{Expr: p.text, Column: t.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseOr parses: and ('||' and)*
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary ('&&' unary)*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+// parseUnary parses: '!' unary | '(' or ')' | comparison
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNot:
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	case tokLParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "expected ) to close ( at column %d, got %s", t.col, closing.describe())
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses: field op value
+func (p *parser) parseComparison() (node, error) {
+	t := p.next()
+	if t.kind != tokWord {
+		return nil, p.errorf(t, "expected a field name, got %s", t.describe())
+	}
+	f, ok := lookupField(t.text)
+	if !ok {
+		return nil, p.errorf(t, "unknown field %q (fields: %s)", t.text, strings.Join(FieldNames(), ", "))
+	}
+	if f.stats {
+		p.stats = true
+	}
+	if f.ctx {
+		p.ctx = true
+	}
+
+	opTok := p.next()
+	if opTok.kind != tokOp {
+		return nil, p.errorf(opTok, "expected an operator after %s (==, !=, <, <=, >, >=, =~, !~), got %s", t.text, opTok.describe())
+	}
+	op := opTok.text
+
+	v := p.next()
+	if v.kind != tokWord && v.kind != tokString {
+		return nil, p.errorf(v, "expected a value after %s, got %s", op, v.describe())
+	}
+
+	n := cmpNode{field: f, op: op}
+	switch {
+	case op == "=~" || op == "!~":
+		if f.kind != kindString {
+			return nil, p.errorf(opTok, "%s only applies to text fields; %s is a %s", op, f.name, f.kind)
+		}
+		re, err := regexp.Compile(v.text)
+		if err != nil {
+			return nil, p.errorf(v, "invalid regular expression: %s", strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+		}
+		n.re = re
+	case f.kind == kindNumber:
+		num, ok := parseNumber(v.text, f.unit)
+		if !ok {
+			return nil, p.errorf(v, "%s is a number; cannot compare it with %s", f.name, v.describe())
+		}
+		n.num = num
+	default:
+		if op != "==" && op != "!=" {
+			return nil, p.errorf(opTok, "%s only applies to numeric fields; %s is a %s", op, f.name, f.kind)
+		}
+		n.str = v.text
+	}
+
+	return n, nil
+}
+
+var numberPattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)([A-Za-z%]*)$`)
+
+// byteUnits are size suffixes, all powers of 1024 like FormatBytes
+var byteUnits = map[string]float64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+// parseNumber parses a number literal, allowing the suffixes of the
+// field's unit
+func parseNumber(text string, u unit) (float64, bool) {
+	if u == unitDuration {
+		if strings.HasSuffix(text, "d") {
+			days, err := strconv.ParseFloat(strings.TrimSuffix(text, "d"), 64)
+			return days * 86400, err == nil
+		}
+		if d, err := time.ParseDuration(text); err == nil {
+			return d.Seconds(), true
+		}
+	}
+
+	m := numberPattern.FindStringSubmatch(text)
+	if m == nil {
+		return 0, false
+	}
+	num, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	suffix := strings.ToLower(m[2])
+	switch {
+	case suffix == "":
+		return num, true
+	case u == unitBytes:
+		scale, ok := byteUnits[suffix]
+		return num * scale, ok
+	case u == unitPercent && suffix == "%":
+		return num, true
+	}
+	return 0, false
+}
+
+// Evaluation
+
+type node interface {
+	eval(l model.Listener) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(l model.Listener) bool { return n.left.eval(l) && n.right.eval(l) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(l model.Listener) bool { return n.left.eval(l) || n.right.eval(l) }
+
+type notNode struct{ inner node }
+
+func (n notNode) eval(l model.Listener) bool { return !n.inner.eval(l) }
+
+// funcNode is a condition added with And
+type funcNode func(l model.Listener) bool
+
+func (n funcNode) eval(l model.Listener) bool { return n(l) }
+
+type cmpNode struct {
+	field *field
+	op    string
+	num   float64
+	str   string
+	re    *regexp.Regexp
+}
+
+func (n cmpNode) eval(l model.Listener) bool {
+	if n.re != nil {
+		matched := n.re.MatchString(n.field.text(l))
+		return matched == (n.op == "=~")
+	}
+
+	if n.field.kind == kindString {
+		equal := n.field.text(l) == n.str
+		return equal == (n.op == "==")
+	}
+
+	v := n.field.number(l)
+	switch n.op {
+	case "==":
+		return v == n.num
+	case "!=":
+		return v != n.num
+	case "<":
+		return v < n.num
+	case "<=":
+		return v <= n.num
+	case ">":
+		return v > n.num
+	case ">=":
+		return v >= n.num
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+var (
+	web = model.Listener{
+		Port:            3000,
+		Protocol:        "tcp",
+		Address:         "127.0.0.1",
+		Service:         &model.Service{Name: "web", Owner: "frontend"},
+		PID:             812,
+		ConnectionCount: 4,
+		Process: &model.Process{
+			PID:           812,
+			Name:          "node",
+			Command:       "node",
+			Cmdline:       []string{"node", "server.js"},
+			User:          "bob",
+			UID:           501,
+			UptimeSeconds: 7200,
+			Project:       &model.Project{Name: "shop"},
+		},
+		Stats: &model.ProcessStats{MemoryRSS: 600 << 20, CPUPercent: 12.5, FDCount: 40, ThreadCount: 11},
+	}
+	postgres = model.Listener{
+		Port:     5432,
+		Protocol: "tcp",
+		Address:  "::",
+		PID:      90,
+		Process:  &model.Process{PID: 90, Name: "postgres", User: "postgres", UID: 70, UptimeSeconds: 3 * 86400},
+	}
+	bare = model.Listener{Port: 53, Protocol: "udp", Address: "0.0.0.0"}
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []bool // web, postgres, bare
+	}{
+		{`port==3000`, []bool{true, false, false}},
+		{`port >= 3000`, []bool{true, true, false}},
+		{`port<1024 || port>5000`, []bool{false, true, true}},
+		{`proto=="udp"`, []bool{false, false, true}},
+		{`protocol != "udp"`, []bool{true, true, false}},
+		{`addr=="127.0.0.1"`, []bool{true, false, false}},
+		{`user=="bob" && conns>0`, []bool{true, false, false}},
+		{`user==bob`, []bool{true, false, false}},
+		{`name=~"^(node|postgres)$"`, []bool{true, true, false}},
+		{`cmd !~ 'node'`, []bool{false, true, true}},
+		{`cmdline=~"server\.js"`, []bool{true, false, false}},
+		{`service=="web" && owner=="frontend"`, []bool{true, false, false}},
+		{`project=="shop"`, []bool{true, false, false}},
+		{`uid<100`, []bool{false, true, true}},
+		{`uptime>1h`, []bool{true, true, false}},
+		{`uptime>=2d`, []bool{false, true, false}},
+		{`uptime<90m`, []bool{false, false, true}},
+		{`rss>500MB`, []bool{true, false, false}},
+		{`mem<=1GiB`, []bool{true, true, true}},
+		{`cpu>10%`, []bool{true, false, false}},
+		{`fds==40 && threads==11`, []bool{true, false, false}},
+		{`!(port==3000)`, []bool{false, true, true}},
+		{`!port==3000 && proto=="tcp"`, []bool{false, true, false}},
+		{`port==53 || port==3000 && user=="nobody"`, []bool{false, false, true}},
+		{`(port==53 || port==3000) && user=="bob"`, []bool{true, false, false}},
+		{`PORT==5432`, []bool{false, true, false}},
+	}
+	listeners := []model.Listener{web, postgres, bare}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			for i, l := range listeners {
+				if got := e.Match(l); got != tt.want[i] {
+					t.Errorf("Match(port %d) = %v, want %v", l.Port, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column int
+		msg    string
+	}{
+		{`port=3000`, 5, "use == to compare"},
+		{`port==abc`, 7, "port is a number"},
+		{`prot==80`, 1, `unknown field "prot"`},
+		{`port`, 5, "expected an operator after port"},
+		{`port==`, 7, "expected a value after =="},
+		{`port==1 port==2`, 9, "expected && or ||"},
+		{`port==1 & port==2`, 9, "expected &&"},
+		{`(port==1`, 9, "expected ) to close ( at column 1"},
+		{`user=="bob`, 7, "unterminated string"},
+		{`user>"bob"`, 5, "> only applies to numeric fields"},
+		{`port=~"3"`, 5, "=~ only applies to text fields"},
+		{`cmd=~"("`, 6, "invalid regular expression"},
+		{`rss>5XB`, 5, "rss is a number"},
+		{`port==1 # x`, 9, "unexpected character"},
+		{``, 1, "expected a field name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("Parse(%q) = %v, want an *Error", tt.expr, err)
+			}
+			if e.Column != tt.column || !strings.Contains(e.Msg, tt.msg) {
+				t.Errorf("Parse(%q) = column %d %q, want column %d %q", tt.expr, e.Column, e.Msg, tt.column, tt.msg)
+			}
+		})
+	}
+}
+
+func TestErrorPointsAtColumn(t *testing.T) {
+	_, err := Parse(`port==abc`)
+	want := "invalid filter at column 7: port is a number; cannot compare it with \"abc\"\n  port==abc\n        ^"
+	if err == nil || err.Error() != want {
+		t.Errorf("error =\n%v\nwant\n%s", err, want)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		text string
+		unit unit
+		want float64
+		ok   bool
+	}{
+		{"42", unitNone, 42, true},
+		{"1.5", unitNone, 1.5, true},
+		{"42k", unitNone, 0, false},
+		{"-1", unitNone, 0, false},
+		{"90", unitDuration, 90, true},
+		{"90s", unitDuration, 90, true},
+		{"1h30m", unitDuration, 5400, true},
+		{"2d", unitDuration, 172800, true},
+		{"xd", unitDuration, 0, false},
+		{"512", unitBytes, 512, true},
+		{"512K", unitBytes, 512 << 10, true},
+		{"100MB", unitBytes, 100 << 20, true},
+		{"1GiB", unitBytes, 1 << 30, true},
+		{"1.5g", unitBytes, 1.5 * (1 << 30), true},
+		{"5XB", unitBytes, 0, false},
+		{"50%", unitPercent, 50, true},
+		{"50", unitPercent, 50, true},
+		{"50MB", unitPercent, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseNumber(tt.text, tt.unit)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseNumber(%q, %d) = %v, %v; want %v, %v", tt.text, tt.unit, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNeeds(t *testing.T) {
+	tests := []struct {
+		expr         string
+		stats, ctx   bool
+		wantAndStats bool
+	}{
+		{`port==3000`, false, false, false},
+		{`rss>1GB`, true, false, true},
+		{`cpu>50% || fds>100`, true, false, true},
+		{`project=="shop"`, false, true, false},
+		{`project=="shop" && threads>1`, true, true, true},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if e.NeedsStats() != tt.stats || e.NeedsContext() != tt.ctx {
+			t.Errorf("Parse(%q): NeedsStats() = %v, NeedsContext() = %v; want %v, %v", tt.expr, e.NeedsStats(), e.NeedsContext(), tt.stats, tt.ctx)
+		}
+
+		and := e.And("port 3000", func(l model.Listener) bool { return l.Port == 3000 }, true)
+		if and.NeedsStats() != tt.wantAndStats || !and.NeedsContext() {
+			t.Errorf("%q And: NeedsStats() = %v, NeedsContext() = %v; want %v, true", tt.expr, and.NeedsStats(), and.NeedsContext(), tt.wantAndStats)
+		}
+	}
+}
+
+func TestAnd(t *testing.T) {
+	inProject := func(l model.Listener) bool { return l.Process != nil && l.Process.Project != nil }
+
+	var none *Expr
+	e := none.And("project shop", inProject, true)
+	if e.String() != "project shop" || !e.NeedsContext() || e.NeedsStats() {
+		t.Errorf("nil.And() = %q, ctx %v, stats %v", e, e.NeedsContext(), e.NeedsStats())
+	}
+	if !e.Match(web) || e.Match(postgres) {
+		t.Error("nil.And() should match only the listener in a project")
+	}
+
+	tcp, err := Parse(`proto=="tcp"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = tcp.And("project shop", inProject, true)
+	if e.String() != `proto=="tcp" && project shop` {
+		t.Errorf("And().String() = %q", e)
+	}
+	if got := e.Filter([]model.Listener{web, postgres, bare}); len(got) != 1 || got[0].Port != 3000 {
+		t.Errorf("And().Filter() = %v, want port 3000 only", got)
+	}
+}
+
+func TestNilExpr(t *testing.T) {
+	var e *Expr
+	listeners := []model.Listener{web, bare}
+	if !e.Match(bare) || len(e.Filter(listeners)) != 2 || e.NeedsStats() || e.NeedsContext() || e.String() != "" {
+		t.Error("a nil Expr should match everything and need nothing")
+	}
+}
+
+func TestFilterKeepsOrder(t *testing.T) {
+	e, err := Parse(`proto=="tcp"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := e.Filter([]model.Listener{postgres, bare, web})
+	if len(got) != 2 || got[0].Port != 5432 || got[1].Port != 3000 {
+		t.Errorf("Filter() = %v, want ports 5432, 3000", got)
+	}
+}
@@ -81,27 +81,38 @@ func IsRunning(pid int) bool {
 	return err == nil
 }
 
-// KillWithTimeout sends SIGTERM, waits for exit, then sends SIGKILL if needed.
-func KillWithTimeout(pid int, timeout time.Duration) error {
-	// First try SIGTERM
-	if err := Kill(pid, syscall.SIGTERM); err != nil {
-		return err
-	}
+// DefaultTimeout is how long to wait for a process to exit after signalling
+// it
+const DefaultTimeout = 5 * time.Second
+
+// Options controls how Terminate signals a process.
+type Options struct {
+	Signal  syscall.Signal
+	Timeout time.Duration // How long to wait for exit after the signal
+}
 
-	// Wait for graceful exit
-	if WaitForExit(pid, timeout) {
-		return nil
+// NewOptions returns the options `portman kill` uses: the named signal, or
+// SIGKILL when force is set, then a wait of timeout for the process to
+// exit. It reports false for an unknown signal name.
+func NewOptions(signal string, force bool, timeout time.Duration) (Options, bool) {
+	if force {
+		return Options{Signal: syscall.SIGKILL, Timeout: timeout}, true
+	}
+	sig, ok := ParseSignal(signal)
+	if !ok {
+		return Options{}, false
 	}
+	return Options{Signal: sig, Timeout: timeout}, true
+}
 
-	// Force kill with SIGKILL
-	if err := Kill(pid, syscall.SIGKILL); err != nil {
+// Terminate sends the configured signal and waits for the process to exit.
+// It returns ErrProcessRunning if the process survived.
+func Terminate(pid int, opts Options) error {
+	if err := Kill(pid, opts.Signal); err != nil {
 		return err
 	}
-
-	// Wait a bit more for SIGKILL to take effect
-	if WaitForExit(pid, 2*time.Second) {
-		return nil
+	if !WaitForExit(pid, opts.Timeout) {
+		return ErrProcessRunning
 	}
-
-	return ErrProcessRunning
+	return nil
 }
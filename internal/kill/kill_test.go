@@ -0,0 +1,70 @@
+package kill
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNewOptions(t *testing.T) {
+	tests := []struct {
+		signal string
+		force  bool
+		want   syscall.Signal
+		ok     bool
+	}{
+		{"TERM", false, syscall.SIGTERM, true},
+		{"sigint", false, syscall.SIGINT, true},
+		{"HUP", false, syscall.SIGHUP, true},
+		{"TERM", true, syscall.SIGKILL, true},
+		{"bogus", true, syscall.SIGKILL, true}, // --force ignores --signal
+		{"bogus", false, 0, false},
+	}
+	for _, tt := range tests {
+		opts, ok := NewOptions(tt.signal, tt.force, time.Second)
+		if ok != tt.ok || opts.Signal != tt.want {
+			t.Errorf("NewOptions(%q, %v) = %v, %v; want %v, %v", tt.signal, tt.force, opts.Signal, ok, tt.want, tt.ok)
+		}
+		if ok && opts.Timeout != time.Second {
+			t.Errorf("NewOptions(%q, %v) = %+v, want a 1s wait", tt.signal, tt.force, opts)
+		}
+	}
+}
+
+// start runs a child process that ignores SIGTERM when stubborn is set
+func start(t *testing.T, stubborn bool) int {
+	t.Helper()
+	script := "exec sleep 30"
+	if stubborn {
+		script = "trap '' TERM; while :; do sleep 0.05; done"
+	}
+	cmd := exec.Command("sh", "-c", script)
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	// Reap the child, so it doesn't linger as a zombie that IsRunning sees
+	go cmd.Wait()
+	t.Cleanup(func() { cmd.Process.Kill() })
+	time.Sleep(100 * time.Millisecond) // Let sh install the trap
+	return cmd.Process.Pid
+}
+
+func TestTerminate(t *testing.T) {
+	t.Run("exits on SIGTERM", func(t *testing.T) {
+		pid := start(t, false)
+		opts, _ := NewOptions("TERM", false, DefaultTimeout)
+		if err := Terminate(pid, opts); err != nil {
+			t.Errorf("Terminate() = %v", err)
+		}
+	})
+
+	t.Run("outlives SIGTERM", func(t *testing.T) {
+		pid := start(t, true)
+		opts, _ := NewOptions("TERM", false, 200*time.Millisecond)
+		if err := Terminate(pid, opts); !errors.Is(err, ErrProcessRunning) {
+			t.Errorf("Terminate() error = %v, want ErrProcessRunning", err)
+		}
+	})
+}
@@ -1,16 +1,100 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
 
 type Process struct {
-	PID           int       `json:"pid"`
-	Name          string    `json:"name"`
-	Command       string    `json:"command"`
-	Cmdline       []string  `json:"cmdline"`
-	User          string    `json:"user"`
-	UID           int       `json:"uid"`
-	StartTime     time.Time `json:"startTime"`
-	UptimeSeconds int64     `json:"uptimeSeconds"`
+	PID           int        `json:"pid"`
+	Name          string     `json:"name"`
+	Command       string     `json:"command"`
+	Cmdline       []string   `json:"cmdline"`
+	User          string     `json:"user"`
+	UID           int        `json:"uid"`
+	StartTime     time.Time  `json:"startTime"`
+	UptimeSeconds int64      `json:"uptimeSeconds"`
+	Cgroup        string     `json:"cgroup,omitempty"`    // Linux cgroup path
+	Container     *Container `json:"container,omitempty"` // Container found in the cgroup path
+
+	// Launch context, collected for detail views and structured output
+	PPID    int              `json:"ppid,omitempty"`
+	Parents []ProcessRef     `json:"parents,omitempty"` // Parent chain, nearest first, up to init
+	TTY     string           `json:"tty,omitempty"`     // Controlling terminal, e.g. "pts/3"
+	Cwd     string           `json:"cwd,omitempty"`     // Working directory
+	Session *TerminalSession `json:"session,omitempty"` // tmux or screen session
+	Project *Project         `json:"project,omitempty"` // Project the working directory belongs to
+}
+
+// Project is the source tree a process was started in, found by walking up
+// from its working directory to a project marker
+type Project struct {
+	Name   string `json:"name"`           // Base name of the root
+	Root   string `json:"root"`           // Nearest directory with a marker
+	Marker string `json:"marker"`         // ".git", "package.json", "go.mod" or "pyproject.toml"
+	Repo   string `json:"repo,omitempty"` // Nearest directory with .git, at or above the root
+}
+
+// Container is the container a process runs in
+type Container struct {
+	ID      string `json:"id"`               // Full 64-hex-digit ID
+	Runtime string `json:"runtime"`          // "docker", "containerd", "podman" or "cri-o"; empty when the path doesn't say
+	Name    string `json:"name,omitempty"`   // From the Docker Engine API
+	Image   string `json:"image,omitempty"`  // From the Docker Engine API
+	PodUID  string `json:"podUid,omitempty"` // Kubernetes pod, when the container belongs to one
+}
+
+// ShortID returns the 12-digit form of the ID that docker ps shows
+func (c *Container) ShortID() string {
+	if c == nil {
+		return ""
+	}
+	if len(c.ID) > 12 {
+		return c.ID[:12]
+	}
+	return c.ID
+}
+
+// Label names the container, by name when known and else by short ID
+func (c *Container) Label() string {
+	if c == nil {
+		return ""
+	}
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.ShortID()
+}
+
+// ProcessRef identifies an ancestor of a process
+type ProcessRef struct {
+	PID     int      `json:"pid"`
+	Name    string   `json:"name"`
+	Cmdline []string `json:"cmdline,omitempty"`
+}
+
+// TerminalSession is the terminal multiplexer session a process runs in
+type TerminalSession struct {
+	Multiplexer string `json:"multiplexer"` // "tmux" or "screen"
+	Name        string `json:"name,omitempty"`
+	Pane        string `json:"pane,omitempty"`
+}
+
+// Label formats the session as e.g. "tmux default (pane %3)"
+func (s *TerminalSession) Label() string {
+	if s == nil {
+		return ""
+	}
+	label := s.Multiplexer
+	if s.Name != "" {
+		label += " " + s.Name
+	}
+	if s.Pane != "" {
+		label += " (pane " + s.Pane + ")"
+	}
+	return label
 }
 
 type Connection struct {
@@ -29,20 +113,122 @@ type ProcessStats struct {
 	ThreadCount int     `json:"threadCount"`
 }
 
+// Service says what a port is for, from the team registry or the system
+// services database
+type Service struct {
+	Name        string `json:"name"`
+	Owner       string `json:"owner,omitempty"`
+	Description string `json:"description,omitempty"`
+	Source      string `json:"source"` // "registry" or "system"
+}
+
+// Label formats the service as "name (owner)", or just the name
+func (s *Service) Label() string {
+	if s == nil {
+		return ""
+	}
+	if s.Owner == "" {
+		return s.Name
+	}
+	return s.Name + " (" + s.Owner + ")"
+}
+
 type Listener struct {
 	Port            int           `json:"port"`
 	Protocol        string        `json:"protocol"`
 	Address         string        `json:"address"`
+	Service         *Service      `json:"service,omitempty"`
 	PID             int           `json:"pid"`
 	Process         *Process      `json:"process,omitempty"`
 	Connections     []Connection  `json:"connections,omitempty"`
 	ConnectionCount int           `json:"connectionCount"`
 	Stats           *ProcessStats `json:"stats,omitempty"`
+	Forward         *Forward      `json:"forward,omitempty"` // Where a published container port leads
 }
 
+// Forward is the second hop of a published container port: the container
+// address the host port is forwarded to, and the process listening there
+type Forward struct {
+	Via           string      `json:"via"` // "docker-proxy", the forwarding process such as "com.docker.backend", or "docker" for a port the kernel forwards without a proxy
+	ContainerIP   string      `json:"containerIp,omitempty"`
+	ContainerPort int         `json:"containerPort"`
+	Container     *Container  `json:"container,omitempty"`
+	Process       *ProcessRef `json:"process,omitempty"` // Listening in the container, with its host PID
+}
+
+// Target formats the container address, e.g. "172.17.0.2:80"
+func (f *Forward) Target() string {
+	if f.ContainerIP == "" {
+		return fmt.Sprintf("container port %d", f.ContainerPort)
+	}
+	return net.JoinHostPort(f.ContainerIP, strconv.Itoa(f.ContainerPort))
+}
+
+// SchemaVersion is the version of the JSON output schema. Bump it when the
+// shape of ScanResult or the types it contains changes after a release.
+const SchemaVersion = 1
+
+// ErrorSchemaVersion is the version of the JSON error schema, which is
+// versioned apart from ScanResult. Bump it when ErrorResult changes after a
+// release.
+const ErrorSchemaVersion = 1
+
+// EventSchemaVersion is the version of the watch event schema, which is
+// versioned apart from ScanResult. Bump it when Event changes after a
+// release.
+const EventSchemaVersion = 1
+
+// ScanResult is the JSON envelope of every command's output. Listeners is
+// nil only in grouped output; NewScanResult makes an empty scan write [].
 type ScanResult struct {
-	Listeners []Listener `json:"listeners"`
-	ScanTime  time.Time  `json:"scanTime"`
-	Platform  string     `json:"platform"`
-	Hostname  string     `json:"hostname"`
+	SchemaVersion int             `json:"schemaVersion"`
+	Listeners     []Listener      `json:"listeners,omitzero" description:"Every listener. Left out with --group-by, where each listener appears once, under groups."`
+	GroupBy       string          `json:"groupBy,omitempty"`
+	Groups        []ListenerGroup `json:"groups,omitzero" description:"Set with --group-by, in place of listeners."`
+	ScanTime      time.Time       `json:"scanTime"`
+	Platform      string          `json:"platform"`
+	Hostname      string          `json:"hostname"`
+}
+
+// ListenerGroup is the listeners sharing a --group-by key, with totals.
+// Memory is counted once per process.
+type ListenerGroup struct {
+	Key         string     `json:"key"`
+	Ports       int        `json:"ports"`
+	Connections int        `json:"connections"`
+	MemoryRSS   int64      `json:"memoryRSS"`
+	Listeners   []Listener `json:"listeners"`
+}
+
+// Event is one line of the NDJSON stream of --watch --json: a change to a
+// listener between two scans, or a failed scan (type "error", with Message
+// and no listener fields)
+type Event struct {
+	SchemaVersion           int       `json:"schemaVersion"`
+	Time                    time.Time `json:"time"`
+	Type                    string    `json:"type"`
+	Port                    int       `json:"port,omitempty"`
+	Protocol                string    `json:"protocol,omitempty"`
+	Address                 string    `json:"address,omitempty"`
+	Service                 string    `json:"service,omitempty"`
+	PID                     int       `json:"pid,omitempty"`
+	Process                 string    `json:"process,omitempty"`
+	ConnectionCount         *int      `json:"connectionCount,omitempty"` // Set on every listener event
+	PreviousPID             int       `json:"previousPid,omitempty"`
+	PreviousProcess         string    `json:"previousProcess,omitempty"`
+	PreviousConnectionCount *int      `json:"previousConnectionCount,omitempty"`
+	Message                 string    `json:"message,omitempty"` // Set on error events
+}
+
+// ErrorResult is written to stderr instead of a ScanResult when a command
+// fails with JSON output
+type ErrorResult struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Error         ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exitCode"`
 }
@@ -0,0 +1,226 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// Column describes a table column drawn from a listener field
+type Column struct {
+	Name     string // Key used with -o columns=
+	Header   string
+	MaxWidth int // Longer values are truncated (0 for no limit)
+	Value    func(l model.Listener) string
+}
+
+// NeedsStats reports whether the column reads process stats, which the
+// scanner only collects on request
+func (c Column) NeedsStats() bool {
+	switch c.Name {
+	case "rss", "cpu", "fds", "threads":
+		return true
+	}
+	return false
+}
+
+// NeedsContext reports whether the column reads the launch context of the
+// process, which the scanner only collects on request
+func (c Column) NeedsContext() bool {
+	return c.Name == "project"
+}
+
+// AllColumns lists every column available for table output
+var AllColumns = []Column{
+	{Name: "port", Header: "PORT", Value: func(l model.Listener) string { return strconv.Itoa(l.Port) }},
+	{Name: "proto", Header: "PROTO", Value: func(l model.Listener) string { return l.Protocol }},
+	{Name: "address", Header: "ADDRESS", MaxWidth: 40, Value: func(l model.Listener) string { return l.Address }},
+	{Name: "service", Header: "SERVICE", MaxWidth: 32, Value: func(l model.Listener) string { return l.Service.Label() }},
+	{Name: "pid", Header: "PID", Value: func(l model.Listener) string {
+		if l.PID <= 0 {
+			return ""
+		}
+		return strconv.Itoa(l.PID)
+	}},
+	{Name: "name", Header: "PROCESS", MaxWidth: 24, Value: processField(func(p *model.Process) string { return p.Name })},
+	{Name: "command", Header: "COMMAND", MaxWidth: 24, Value: commandName},
+	{Name: "cmdline", Header: "CMDLINE", MaxWidth: 80, Value: processField(func(p *model.Process) string { return strings.Join(p.Cmdline, " ") })},
+	{Name: "user", Header: "USER", MaxWidth: 16, Value: processField(func(p *model.Process) string { return p.User })},
+	{Name: "uid", Header: "UID", Value: processField(func(p *model.Process) string { return strconv.Itoa(p.UID) })},
+	{Name: "started", Header: "STARTED", Value: processField(func(p *model.Process) string {
+		if p.StartTime.IsZero() {
+			return ""
+		}
+		return p.StartTime.Format("2006-01-02 15:04:05")
+	})},
+	{Name: "uptime", Header: "UPTIME", Value: processField(func(p *model.Process) string {
+		if p.UptimeSeconds <= 0 {
+			return ""
+		}
+		return FormatDuration(p.UptimeSeconds)
+	})},
+	{Name: "project", Header: "PROJECT", MaxWidth: 24, Value: processField(func(p *model.Process) string {
+		if p.Project == nil {
+			return ""
+		}
+		return p.Project.Name
+	})},
+	{Name: "container", Header: "CONTAINER", MaxWidth: 24, Value: func(l model.Listener) string { return listenerContainer(l).Label() }},
+	{Name: "cgroup", Header: "CGROUP", MaxWidth: 48, Value: processField(func(p *model.Process) string { return p.Cgroup })},
+	{Name: "conns", Header: "CONNS", Value: func(l model.Listener) string { return strconv.Itoa(l.ConnectionCount) }},
+	{Name: "rss", Header: "RSS", Value: statsField(func(s *model.ProcessStats) string { return FormatBytes(s.MemoryRSS) })},
+	{Name: "cpu", Header: "CPU", Value: statsField(func(s *model.ProcessStats) string { return fmt.Sprintf("%.1f%%", s.CPUPercent) })},
+	{Name: "fds", Header: "FDS", Value: statsField(func(s *model.ProcessStats) string { return strconv.Itoa(s.FDCount) })},
+	{Name: "threads", Header: "THREADS", Value: statsField(func(s *model.ProcessStats) string { return strconv.Itoa(s.ThreadCount) })},
+}
+
+// columnAliases maps alternative names to column names
+var columnAliases = map[string]string{
+	"protocol":    "proto",
+	"addr":        "address",
+	"svc":         "service",
+	"process":     "name",
+	"cmd":         "command",
+	"args":        "cmdline",
+	"start":       "started",
+	"connections": "conns",
+	"memory":      "rss",
+	"mem":         "rss",
+}
+
+// DefaultColumns is the column set of the default table layout
+var DefaultColumns = []string{"port", "proto", "pid", "user", "command", "conns", "uptime"}
+
+// DefaultWatchColumns is the column set of the all-ports watch view
+var DefaultWatchColumns = []string{"port", "proto", "pid", "user", "conns", "uptime", "name"}
+
+// LookupColumns resolves column names, in order, to columns
+func LookupColumns(names []string) ([]Column, error) {
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := columnAliases[name]; ok {
+			name = alias
+		}
+
+		found := false
+		for _, c := range AllColumns {
+			if c.Name == name {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column: %s (available: %s)", name, strings.Join(ColumnNames(), ", "))
+		}
+	}
+	return columns, nil
+}
+
+// ParseColumns resolves a comma-separated column list such as
+// "port,pid,cmdline"
+func ParseColumns(spec string) ([]Column, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, fmt.Errorf("no columns given (available: %s)", strings.Join(ColumnNames(), ", "))
+	}
+	return LookupColumns(strings.Split(spec, ","))
+}
+
+// ColumnNames returns the names of all available columns
+func ColumnNames() []string {
+	names := make([]string, len(AllColumns))
+	for i, c := range AllColumns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// NeedStats reports whether any of the columns reads process stats
+func NeedStats(columns []Column) bool {
+	for _, c := range columns {
+		if c.NeedsStats() {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedContext reports whether any of the columns reads launch context
+func NeedContext(columns []Column) bool {
+	for _, c := range columns {
+		if c.NeedsContext() {
+			return true
+		}
+	}
+	return false
+}
+
+// Cells returns the column values for a listener, truncated to each
+// column's maximum width. Empty values are shown as "-".
+func Cells(columns []Column, l model.Listener) []string {
+	cells := make([]string, len(columns))
+	for i, c := range columns {
+		v := c.Value(l)
+		if v == "" {
+			v = "-"
+		}
+		if c.MaxWidth > 0 {
+			v = truncate(v, c.MaxWidth)
+		}
+		cells[i] = v
+	}
+	return cells
+}
+
+// Headers returns the column headers
+func Headers(columns []Column) []string {
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Header
+	}
+	return headers
+}
+
+func processField(get func(p *model.Process) string) func(model.Listener) string {
+	return func(l model.Listener) string {
+		if l.Process == nil {
+			return ""
+		}
+		return get(l.Process)
+	}
+}
+
+func statsField(get func(s *model.ProcessStats) string) func(model.Listener) string {
+	return func(l model.Listener) string {
+		if l.Stats == nil {
+			return ""
+		}
+		return get(l.Stats)
+	}
+}
+
+// commandName returns the process command, falling back to its name
+func commandName(l model.Listener) string {
+	if l.Process == nil {
+		return ""
+	}
+	if l.Process.Command != "" {
+		return l.Process.Command
+	}
+	return l.Process.Name
+}
+
+// listenerContainer returns the container a listener's process runs in or,
+// for a published port, the container it forwards to
+func listenerContainer(l model.Listener) *model.Container {
+	if l.Process != nil && l.Process.Container != nil {
+		return l.Process.Container
+	}
+	if l.Forward != nil {
+		return l.Forward.Container
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string // Column names, comma-separated
+		context bool
+		stats   bool
+	}{
+		{"port,pid,cmdline", "port,pid,cmdline", false, false},
+		{" PORT , Protocol,addr ", "port,proto,address", false, false},
+		{"process,cmd,args,start,connections", "name,command,cmdline,started,conns", false, false},
+		{"port,mem", "port,rss", false, true},
+		{"port,cpu,fds,threads", "port,cpu,fds,threads", false, true},
+		{"project,port", "project,port", true, false},
+		{"port,port", "port,port", false, false},
+	}
+	for _, tt := range tests {
+		columns, err := ParseColumns(tt.spec)
+		if err != nil {
+			t.Errorf("ParseColumns(%q): %v", tt.spec, err)
+			continue
+		}
+		names := make([]string, len(columns))
+		for i, c := range columns {
+			names[i] = c.Name
+		}
+		if got := strings.Join(names, ","); got != tt.want {
+			t.Errorf("ParseColumns(%q) = %s, want %s", tt.spec, got, tt.want)
+		}
+		if NeedContext(columns) != tt.context || NeedStats(columns) != tt.stats {
+			t.Errorf("ParseColumns(%q): NeedContext() = %v, NeedStats() = %v; want %v, %v", tt.spec, NeedContext(columns), NeedStats(columns), tt.context, tt.stats)
+		}
+	}
+}
+
+func TestParseColumnsErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"", "no columns given"},
+		{"  ", "no columns given"},
+		{"port,nope", "unknown column: nope"},
+		{"port,", "unknown column: "},
+	}
+	for _, tt := range tests {
+		_, err := ParseColumns(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseColumns(%q) = %v, want an error containing %q", tt.spec, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultColumnsExist(t *testing.T) {
+	for _, names := range [][]string{DefaultColumns, DefaultWatchColumns} {
+		if _, err := LookupColumns(names); err != nil {
+			t.Errorf("LookupColumns(%v): %v", names, err)
+		}
+	}
+}
+
+func TestCells(t *testing.T) {
+	columns, err := ParseColumns("port,pid,user,cmdline,address,container,rss,uptime")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := model.Listener{
+		Port:    8080,
+		Address: "fe80::1ff:fe23:4567:890a%eth0-with-a-very-long-zone-name",
+		PID:     812,
+		Process: &model.Process{
+			User:          "bob",
+			Cmdline:       []string{"node", strings.Repeat("x", 100)},
+			UptimeSeconds: 90,
+			Container:     &model.Container{ID: strings.Repeat("ab", 32), Runtime: "docker"},
+		},
+		Stats: &model.ProcessStats{MemoryRSS: 3 << 20},
+	}
+	got := Cells(columns, l)
+	want := []string{
+		"8080",
+		"812",
+		"bob",
+		"node " + strings.Repeat("x", 72) + "...",
+		"fe80::1ff:fe23:4567:890a%eth0-with-a-...",
+		"abababababab",
+		"3.0 MB",
+		"1m30s",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Cells() =\n%q\nwant\n%q", got, want)
+	}
+
+	// Missing values show as "-"
+	got = Cells(columns, model.Listener{Port: 53})
+	if strings.Join(got, ",") != "53,-,-,-,-,-,-,-" {
+		t.Errorf("Cells() of a bare listener = %q", got)
+	}
+}
+
+func TestCellsTruncateRunes(t *testing.T) {
+	columns, err := ParseColumns("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := model.Listener{Process: &model.Process{User: "ünïcödé-üsér-nämé"}}
+	if got := Cells(columns, l)[0]; got != "ünïcödé-üsér-..." {
+		t.Errorf("Cells() = %q, want %q", got, "ünïcödé-üsér-...")
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	columns, err := ParseColumns("port,process,conns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(Headers(columns), " "); got != "PORT PROCESS CONNS" {
+		t.Errorf("Headers() = %s", got)
+	}
+}
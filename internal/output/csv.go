@@ -0,0 +1,72 @@
+package output
+
+import (
+	"encoding/csv"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// CSVFormatter writes one row per listener with the flattened Fields as
+// columns. As TSV, values are tab separated and never quoted; tabs and
+// newlines inside values are replaced with spaces.
+type CSVFormatter struct {
+	NoHeader bool
+	TSV      bool
+}
+
+func NewCSVFormatter() *CSVFormatter {
+	return &CSVFormatter{}
+}
+
+func NewTSVFormatter() *CSVFormatter {
+	return &CSVFormatter{TSV: true}
+}
+
+func (f *CSVFormatter) Format(listeners []model.Listener) (string, error) {
+	var rows [][]string
+	if !f.NoHeader {
+		rows = append(rows, FieldKeys())
+	}
+	for _, l := range listeners {
+		row := make([]string, len(Fields))
+		for i, field := range Fields {
+			row[i] = flatString(field.Value(l))
+		}
+		rows = append(rows, row)
+	}
+
+	if f.TSV {
+		return formatTSV(rows), nil
+	}
+
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func (f *CSVFormatter) FormatSingle(listener *model.Listener) (string, error) {
+	if listener == nil {
+		return f.Format(nil)
+	}
+	return f.Format([]model.Listener{*listener})
+}
+
+var tsvEscaper = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+func formatTSV(rows [][]string) string {
+	var sb strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			if i > 0 {
+				sb.WriteByte('\t')
+			}
+			sb.WriteString(tsvEscaper.Replace(cell))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,173 @@
+package output
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// Field is one flattened listener value, shared by the CSV, TSV, and YAML
+// formatters. Nested process and stats fields use dotted keys named after
+// their JSON fields, e.g. "process.name".
+type Field struct {
+	Key   string
+	Value func(l model.Listener) any // nil when the value is unavailable
+}
+
+// Fields lists the flattened listener fields in output order
+var Fields = []Field{
+	{Key: "port", Value: func(l model.Listener) any { return l.Port }},
+	{Key: "protocol", Value: func(l model.Listener) any { return l.Protocol }},
+	{Key: "address", Value: func(l model.Listener) any { return l.Address }},
+	{Key: "service.name", Value: serviceValue(func(s *model.Service) string { return s.Name })},
+	{Key: "service.owner", Value: serviceValue(func(s *model.Service) string { return s.Owner })},
+	{Key: "service.description", Value: serviceValue(func(s *model.Service) string { return s.Description })},
+	{Key: "service.source", Value: serviceValue(func(s *model.Service) string { return s.Source })},
+	{Key: "pid", Value: func(l model.Listener) any { return l.PID }},
+	{Key: "connectionCount", Value: func(l model.Listener) any { return l.ConnectionCount }},
+	{Key: "process.name", Value: processValue(func(p *model.Process) any { return p.Name })},
+	{Key: "process.command", Value: processValue(func(p *model.Process) any { return p.Command })},
+	{Key: "process.cmdline", Value: processValue(func(p *model.Process) any { return p.Cmdline })},
+	{Key: "process.user", Value: processValue(func(p *model.Process) any { return p.User })},
+	{Key: "process.uid", Value: processValue(func(p *model.Process) any { return p.UID })},
+	{Key: "process.startTime", Value: processValue(func(p *model.Process) any { return p.StartTime })},
+	{Key: "process.uptimeSeconds", Value: processValue(func(p *model.Process) any { return p.UptimeSeconds })},
+	{Key: "process.cgroup", Value: processValue(func(p *model.Process) any { return omitEmpty(p.Cgroup) })},
+	{Key: "process.container.id", Value: containerValue(func(c *model.Container) string { return c.ID })},
+	{Key: "process.container.runtime", Value: containerValue(func(c *model.Container) string { return c.Runtime })},
+	{Key: "process.container.name", Value: containerValue(func(c *model.Container) string { return c.Name })},
+	{Key: "process.container.image", Value: containerValue(func(c *model.Container) string { return c.Image })},
+	{Key: "process.container.podUid", Value: containerValue(func(c *model.Container) string { return c.PodUID })},
+	{Key: "process.ppid", Value: processValue(func(p *model.Process) any {
+		if p.PPID == 0 {
+			return nil
+		}
+		return p.PPID
+	})},
+	{Key: "process.tty", Value: processValue(func(p *model.Process) any { return omitEmpty(p.TTY) })},
+	{Key: "process.cwd", Value: processValue(func(p *model.Process) any { return omitEmpty(p.Cwd) })},
+	{Key: "process.session", Value: processValue(func(p *model.Process) any { return omitEmpty(p.Session.Label()) })},
+	{Key: "process.project.name", Value: projectValue(func(p *model.Project) string { return p.Name })},
+	{Key: "process.project.root", Value: projectValue(func(p *model.Project) string { return p.Root })},
+	{Key: "forward.via", Value: forwardValue(func(f *model.Forward) any { return f.Via })},
+	{Key: "forward.containerIp", Value: forwardValue(func(f *model.Forward) any { return omitEmpty(f.ContainerIP) })},
+	{Key: "forward.containerPort", Value: forwardValue(func(f *model.Forward) any { return f.ContainerPort })},
+	{Key: "forward.container.name", Value: forwardValue(func(f *model.Forward) any { return omitEmpty(f.Container.Label()) })},
+	{Key: "forward.process.pid", Value: forwardValue(func(f *model.Forward) any {
+		if f.Process == nil {
+			return nil
+		}
+		return f.Process.PID
+	})},
+	{Key: "forward.process.name", Value: forwardValue(func(f *model.Forward) any {
+		if f.Process == nil {
+			return nil
+		}
+		return f.Process.Name
+	})},
+	{Key: "stats.memoryRSS", Value: statsValue(func(s *model.ProcessStats) any { return s.MemoryRSS })},
+	{Key: "stats.cpuPercent", Value: statsValue(func(s *model.ProcessStats) any { return s.CPUPercent })},
+	{Key: "stats.fdCount", Value: statsValue(func(s *model.ProcessStats) any { return s.FDCount })},
+	{Key: "stats.threadCount", Value: statsValue(func(s *model.ProcessStats) any { return s.ThreadCount })},
+}
+
+// FieldKeys returns the keys of all flattened fields
+func FieldKeys() []string {
+	keys := make([]string, len(Fields))
+	for i, f := range Fields {
+		keys[i] = f.Key
+	}
+	return keys
+}
+
+// flatString renders a field value as a single string. Missing values and
+// zero times are empty, and command lines are joined with spaces.
+func flatString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		return strings.Join(v, " ")
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return ""
+	}
+}
+
+// omitEmpty returns nil for "", as the JSON output omits empty strings
+func omitEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func processValue(get func(p *model.Process) any) func(model.Listener) any {
+	return func(l model.Listener) any {
+		if l.Process == nil {
+			return nil
+		}
+		return get(l.Process)
+	}
+}
+
+// containerValue omits empty container values, as the JSON output does
+func containerValue(get func(c *model.Container) string) func(model.Listener) any {
+	return func(l model.Listener) any {
+		if l.Process == nil || l.Process.Container == nil {
+			return nil
+		}
+		return omitEmpty(get(l.Process.Container))
+	}
+}
+
+func forwardValue(get func(f *model.Forward) any) func(model.Listener) any {
+	return func(l model.Listener) any {
+		if l.Forward == nil {
+			return nil
+		}
+		return get(l.Forward)
+	}
+}
+
+func projectValue(get func(p *model.Project) string) func(model.Listener) any {
+	return func(l model.Listener) any {
+		if l.Process == nil || l.Process.Project == nil {
+			return nil
+		}
+		return get(l.Process.Project)
+	}
+}
+
+// serviceValue omits empty service values, as the JSON output does
+func serviceValue(get func(s *model.Service) string) func(model.Listener) any {
+	return func(l model.Listener) any {
+		if l.Service == nil || get(l.Service) == "" {
+			return nil
+		}
+		return get(l.Service)
+	}
+}
+
+func statsValue(get func(s *model.ProcessStats) any) func(model.Listener) any {
+	return func(l model.Listener) any {
+		if l.Stats == nil {
+			return nil
+		}
+		return get(l.Stats)
+	}
+}
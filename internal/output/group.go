@@ -0,0 +1,150 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// groupKeys maps each --group-by value to the key of a listener's group
+var groupKeys = map[string]func(l model.Listener) string{
+	"process": func(l model.Listener) string { return commandName(l) },
+	"user":    processField(func(p *model.Process) string { return p.User }),
+	"pid": func(l model.Listener) string {
+		if l.PID <= 0 {
+			return ""
+		}
+		return strconv.Itoa(l.PID)
+	},
+	"container": func(l model.Listener) string { return listenerContainer(l).Label() },
+	"cgroup":    processField(func(p *model.Process) string { return p.Cgroup }),
+}
+
+// GroupByNames returns the valid --group-by values
+func GroupByNames() []string {
+	return []string{"process", "user", "pid", "container", "cgroup"}
+}
+
+// ValidGroupBy reports whether by is a --group-by value
+func ValidGroupBy(by string) bool {
+	_, ok := groupKeys[by]
+	return ok
+}
+
+// GroupListeners groups listeners by a --group-by value, keeping the order
+// of the listeners: groups appear in the order of their first listener.
+// Listeners without a key share a group with an empty key.
+func GroupListeners(listeners []model.Listener, by string) []model.ListenerGroup {
+	key := groupKeys[by]
+	if key == nil {
+		return nil
+	}
+
+	var groups []model.ListenerGroup
+	index := make(map[string]int)
+	counted := make(map[string]map[int]bool) // PIDs whose memory is counted, per group
+	for _, l := range listeners {
+		k := key(l)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			counted[k] = make(map[int]bool)
+			groups = append(groups, model.ListenerGroup{Key: k})
+		}
+
+		g := &groups[i]
+		g.Listeners = append(g.Listeners, l)
+		g.Ports++
+		g.Connections += l.ConnectionCount
+		if l.Stats != nil && !counted[k][l.PID] {
+			counted[k][l.PID] = true
+			g.MemoryRSS += l.Stats.MemoryRSS
+		}
+	}
+	return groups
+}
+
+// FormatGroups renders groups as a tree: a row per group with its totals,
+// and the group's listeners under it as table rows
+func (f *TableFormatter) FormatGroups(groups []model.ListenerGroup, by string) string {
+	if len(groups) == 0 {
+		return "No listening ports found."
+	}
+
+	headers := Headers(f.Columns)
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+	}
+	rows := make([][][]string, len(groups))
+	for gi, g := range groups {
+		for _, l := range g.Listeners {
+			row := Cells(f.Columns, l)
+			for i, cell := range row {
+				widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+			}
+			rows[gi] = append(rows[gi], row)
+		}
+	}
+
+	var sb strings.Builder
+	if !f.NoHeader {
+		sb.WriteString("   " + formatRow(headers, widths))
+	}
+
+	for gi, g := range groups {
+		if gi > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("%s  (%s)\n", groupLabel(g, by), groupTotals(g)))
+		for ri, row := range rows[gi] {
+			branch := "├─ "
+			if ri == len(rows[gi])-1 {
+				branch = "└─ "
+			}
+			sb.WriteString(branch + formatRow(row, widths))
+		}
+	}
+	return sb.String()
+}
+
+// groupLabel names a group in the tree
+func groupLabel(g model.ListenerGroup, by string) string {
+	if g.Key == "" {
+		switch by {
+		case "container":
+			return "(no container)"
+		case "cgroup":
+			return "(no cgroup)"
+		}
+		return "(unknown)"
+	}
+	if by == "pid" {
+		if name := commandName(g.Listeners[0]); name != "" {
+			return fmt.Sprintf("%s (pid %s)", name, g.Key)
+		}
+		return "pid " + g.Key
+	}
+	return g.Key
+}
+
+// groupTotals summarizes a group's ports, connections and memory
+func groupTotals(g model.ListenerGroup) string {
+	parts := []string{plural(g.Ports, "port"), plural(g.Connections, "conn")}
+	if slices.ContainsFunc(g.Listeners, func(l model.Listener) bool { return l.Stats != nil }) {
+		parts = append(parts, FormatBytes(g.MemoryRSS))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,170 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// groupListener builds a listener for group tests
+func groupListener(port, pid int, name, user string, conns int, rss int64) model.Listener {
+	l := model.Listener{Port: port, Protocol: "tcp", Address: "::", PID: pid, ConnectionCount: conns}
+	if pid > 0 {
+		l.Process = &model.Process{PID: pid, Name: name, Command: name, User: user}
+	}
+	if rss > 0 {
+		l.Stats = &model.ProcessStats{MemoryRSS: rss}
+	}
+	return l
+}
+
+// groupSummary renders groups as "key:ports/conns/rss[port port]"
+func groupSummary(groups []model.ListenerGroup) string {
+	var parts []string
+	for _, g := range groups {
+		var ports []string
+		for _, l := range g.Listeners {
+			ports = append(ports, fmt.Sprint(l.Port))
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d/%d/%d[%s]", g.Key, g.Ports, g.Connections, g.MemoryRSS, strings.Join(ports, " ")))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestGroupListeners(t *testing.T) {
+	docker := &model.Container{ID: strings.Repeat("ab", 32), Runtime: "docker", Name: "web"}
+	listeners := []model.Listener{
+		groupListener(3000, 812, "node", "bob", 4, 100),
+		groupListener(5432, 90, "postgres", "pg", 3, 50),
+		groupListener(9229, 812, "node", "bob", 0, 100), // Same process: memory counted once
+		groupListener(3001, 813, "node", "bob", 1, 10),
+		groupListener(53, 0, "", "", 0, 0),
+		{Port: 8080, Protocol: "tcp", Forward: &model.Forward{Via: "docker-proxy", Container: docker}},
+	}
+
+	tests := []struct {
+		by   string
+		want string
+	}{
+		{"process", "node:3/5/110[3000 9229 3001] postgres:1/3/50[5432] :2/0/0[53 8080]"},
+		{"user", "bob:3/5/110[3000 9229 3001] pg:1/3/50[5432] :2/0/0[53 8080]"},
+		{"pid", "812:2/4/100[3000 9229] 90:1/3/50[5432] 813:1/1/10[3001] :2/0/0[53 8080]"},
+		{"container", ":5/8/160[3000 5432 9229 3001 53] web:1/0/0[8080]"},
+	}
+	for _, tt := range tests {
+		if got := groupSummary(GroupListeners(listeners, tt.by)); got != tt.want {
+			t.Errorf("GroupListeners(%s) =\n%s\nwant\n%s", tt.by, got, tt.want)
+		}
+	}
+
+	if groups := GroupListeners(listeners, "bogus"); groups != nil {
+		t.Errorf("GroupListeners(bogus) = %v, want nil", groups)
+	}
+}
+
+func TestGroupByNames(t *testing.T) {
+	for _, by := range GroupByNames() {
+		if !ValidGroupBy(by) {
+			t.Errorf("ValidGroupBy(%q) = false", by)
+		}
+	}
+	if ValidGroupBy("port") || ValidGroupBy("") {
+		t.Error("ValidGroupBy accepts keys it can't group by")
+	}
+}
+
+func TestGroupLabel(t *testing.T) {
+	node := groupListener(3000, 812, "node", "bob", 0, 0)
+	tests := []struct {
+		group model.ListenerGroup
+		by    string
+		want  string
+	}{
+		{model.ListenerGroup{Key: "bob"}, "user", "bob"},
+		{model.ListenerGroup{Key: "812", Listeners: []model.Listener{node}}, "pid", "node (pid 812)"},
+		{model.ListenerGroup{Key: "812", Listeners: []model.Listener{{PID: 812}}}, "pid", "pid 812"},
+		{model.ListenerGroup{}, "container", "(no container)"},
+		{model.ListenerGroup{}, "cgroup", "(no cgroup)"},
+		{model.ListenerGroup{}, "user", "(unknown)"},
+	}
+	for _, tt := range tests {
+		if got := groupLabel(tt.group, tt.by); got != tt.want {
+			t.Errorf("groupLabel(%q, %s) = %q, want %q", tt.group.Key, tt.by, got, tt.want)
+		}
+	}
+}
+
+func TestFormatGroups(t *testing.T) {
+	listeners := []model.Listener{
+		groupListener(3000, 812, "node", "bob", 4, 50<<20),
+		groupListener(9229, 812, "node", "bob", 0, 50<<20),
+		groupListener(5432, 90, "postgres", "pg", 1, 0),
+	}
+	columns, err := ParseColumns("port,pid,conns")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewTableFormatter()
+	f.Columns = columns
+	got := f.FormatGroups(GroupListeners(listeners, "process"), "process")
+	want := `   PORT  PID  CONNS
+node  (2 ports, 4 conns, 50.0 MB)
+├─ 3000  812  4
+└─ 9229  812  0
+
+postgres  (1 port, 1 conn)
+└─ 5432  90   1
+`
+	if got != want {
+		t.Errorf("FormatGroups() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := f.FormatGroups(nil, "process"); got != "No listening ports found." {
+		t.Errorf("FormatGroups(nil) = %q", got)
+	}
+}
+
+func TestFormatGroupsJSONListsEachListenerOnce(t *testing.T) {
+	listeners := []model.Listener{
+		groupListener(3000, 812, "node", "bob", 0, 0),
+		groupListener(5432, 90, "postgres", "pg", 0, 0),
+	}
+	for _, tt := range []struct {
+		groups []model.ListenerGroup
+		ports  int
+	}{
+		{GroupListeners(listeners, "user"), len(listeners)},
+		{nil, 0},
+	} {
+		out, err := NewJSONFormatter(false).FormatGroups(tt.groups, "user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := result["listeners"]; ok {
+			t.Errorf("grouped output has top-level listeners: %s", out)
+		}
+		if got := strings.Count(out, `"port":`); got != tt.ports {
+			t.Errorf("grouped output lists %d listeners, want %d: %s", got, tt.ports, out)
+		}
+		if string(result["groups"]) == "" || string(result["groups"]) == "null" {
+			t.Errorf("grouped output has no groups list: %s", out)
+		}
+	}
+
+	// Ungrouped output keeps an empty list
+	out, err := NewJSONFormatter(false).Format(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `"listeners":[]`) || strings.Contains(out, `"groups"`) {
+		t.Errorf("Format(nil) = %s", out)
+	}
+}
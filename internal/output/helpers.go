@@ -3,23 +3,22 @@ package output
 import (
 	"fmt"
 	"runtime"
-	"sort"
-	"strings"
 	"time"
-
-	"github.com/tasnimzotder/portman/internal/model"
 )
 
+// truncate shortens s to max characters, ending it with "..." when there's
+// room
 func truncate(s string, max int) string {
-	if len(s) <= max {
+	runes := []rune(s)
+	if len(runes) <= max {
 		return s
 	}
 
 	if max <= 3 {
-		return s[:max]
+		return string(runes[:max])
 	}
 
-	return s[:max-3] + "..."
+	return string(runes[:max-3]) + "..."
 }
 
 // FormatDuration formats seconds into a human-readable duration
@@ -47,36 +46,3 @@ func FormatBytes(bytes int64) string {
 func getPlatform() string {
 	return runtime.GOOS
 }
-
-// SortListeners sorts listeners by the specified field.
-// Valid fields: port, pid, user, conns, uptime
-func SortListeners(listeners []model.Listener, by string) {
-	sort.Slice(listeners, func(i, j int) bool {
-		switch strings.ToLower(by) {
-		case "pid":
-			return listeners[i].PID < listeners[j].PID
-		case "user":
-			ui, uj := "", ""
-			if listeners[i].Process != nil {
-				ui = listeners[i].Process.User
-			}
-			if listeners[j].Process != nil {
-				uj = listeners[j].Process.User
-			}
-			return ui < uj
-		case "conns":
-			return listeners[i].ConnectionCount > listeners[j].ConnectionCount // Descending
-		case "uptime":
-			ui, uj := int64(0), int64(0)
-			if listeners[i].Process != nil {
-				ui = listeners[i].Process.UptimeSeconds
-			}
-			if listeners[j].Process != nil {
-				uj = listeners[j].Process.UptimeSeconds
-			}
-			return ui > uj // Descending (longest uptime first)
-		default: // "port"
-			return listeners[i].Port < listeners[j].Port
-		}
-	})
-}
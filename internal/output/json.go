@@ -16,44 +16,55 @@ func NewJSONFormatter(pretty bool) *JSONFormatter {
 	return &JSONFormatter{Pretty: pretty}
 }
 
-func (f *JSONFormatter) Format(listeners []model.Listener) (string, error) {
+// NewScanResult wraps listeners with the schema version, scan time,
+// platform, and hostname
+func NewScanResult(listeners []model.Listener) model.ScanResult {
 	hostname, _ := os.Hostname()
 
-	result := model.ScanResult{
-		Listeners: listeners,
-		ScanTime:  time.Now().UTC(),
-		Platform:  getPlatform(),
-		Hostname:  hostname,
+	if listeners == nil {
+		listeners = []model.Listener{}
 	}
 
-	var data []byte
-	var err error
-
-	if f.Pretty {
-		data, err = json.MarshalIndent(result, "", "  ")
-	} else {
-		data, err = json.Marshal(result)
+	return model.ScanResult{
+		SchemaVersion: model.SchemaVersion,
+		Listeners:     listeners,
+		ScanTime:      time.Now().UTC(),
+		Platform:      getPlatform(),
+		Hostname:      hostname,
 	}
+}
 
-	if err != nil {
-		return "", err
-	}
+func (f *JSONFormatter) Format(listeners []model.Listener) (string, error) {
+	return f.marshal(NewScanResult(listeners))
+}
 
-	return string(data), nil
+// FormatGroups writes the envelope with the listeners under their
+// --group-by groups instead of in the top-level list
+func (f *JSONFormatter) FormatGroups(groups []model.ListenerGroup, by string) (string, error) {
+	return f.marshal(newGroupedResult(groups, by))
 }
 
-func (f *JSONFormatter) FormatSingle(listener *model.Listener) (string, error) {
-	if listener == nil {
-		return "{}", nil
+// newGroupedResult returns the envelope of grouped output. Each listener
+// appears once, in its group, so the top-level listeners are left out.
+func newGroupedResult(groups []model.ListenerGroup, by string) model.ScanResult {
+	if groups == nil {
+		groups = []model.ListenerGroup{}
 	}
+	result := NewScanResult(nil)
+	result.Listeners = nil
+	result.GroupBy = by
+	result.Groups = groups
+	return result
+}
 
+func (f *JSONFormatter) marshal(result model.ScanResult) (string, error) {
 	var data []byte
 	var err error
 
 	if f.Pretty {
-		data, err = json.MarshalIndent(listener, "", "  ")
+		data, err = json.MarshalIndent(result, "", "  ")
 	} else {
-		data, err = json.Marshal(listener)
+		data, err = json.Marshal(result)
 	}
 
 	if err != nil {
@@ -62,3 +73,12 @@ func (f *JSONFormatter) FormatSingle(listener *model.Listener) (string, error) {
 
 	return string(data), nil
 }
+
+// FormatSingle uses the same envelope as Format, with one listener, or
+// none when the port is not in use
+func (f *JSONFormatter) FormatSingle(listener *model.Listener) (string, error) {
+	if listener == nil {
+		return f.Format(nil)
+	}
+	return f.Format([]model.Listener{*listener})
+}
@@ -0,0 +1,260 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// Report is the content of `portman report`: a scan, the detail of selected
+// ports, and exposure warnings
+type Report struct {
+	model.ScanResult
+	Details  []model.Listener // Selected ports, with connections and stats
+	Missing  []int            // Selected ports that are not in use
+	Warnings []ExposureWarning
+}
+
+// ExposureWarning flags a listener reachable from other hosts
+type ExposureWarning struct {
+	Port     int
+	Protocol string
+	Address  string
+	Process  string
+	PID      int
+	Severity string // "high" for well-known services that are rarely meant to be public, otherwise "warning"
+	Message  string
+}
+
+// sensitivePorts are services that should rarely listen on all interfaces
+var sensitivePorts = map[int]string{
+	2375:  "Docker API",
+	3306:  "MySQL",
+	5432:  "PostgreSQL",
+	5984:  "CouchDB",
+	6379:  "Redis",
+	9042:  "Cassandra",
+	9200:  "Elasticsearch",
+	11211: "Memcached",
+	27017: "MongoDB",
+}
+
+// NewReport builds a report from a scan and the details of selected ports
+func NewReport(result model.ScanResult, details []model.Listener, missing []int) *Report {
+	return &Report{
+		ScanResult: result,
+		Details:    details,
+		Missing:    missing,
+		Warnings:   ExposureWarnings(result.Listeners),
+	}
+}
+
+// ExposureWarnings returns a warning for every listener bound to all
+// interfaces, most severe first
+func ExposureWarnings(listeners []model.Listener) []ExposureWarning {
+	var warnings []ExposureWarning
+	for _, l := range listeners {
+		if !isWildcard(l.Address) {
+			continue
+		}
+
+		w := ExposureWarning{
+			Port:     l.Port,
+			Protocol: l.Protocol,
+			Address:  l.Address,
+			PID:      l.PID,
+			Severity: "warning",
+			Message:  "listens on all interfaces",
+		}
+		if l.Process != nil {
+			w.Process = l.Process.Name
+		}
+		if service, ok := sensitivePorts[l.Port]; ok {
+			w.Severity = "high"
+			w.Message = fmt.Sprintf("%s listens on all interfaces", service)
+		}
+		warnings = append(warnings, w)
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		if warnings[i].Severity != warnings[j].Severity {
+			return warnings[i].Severity == "high"
+		}
+		return warnings[i].Port < warnings[j].Port
+	})
+	return warnings
+}
+
+// isWildcard reports whether an address binds all interfaces
+func isWildcard(addr string) bool {
+	switch addr {
+	case "", "*", "0.0.0.0", "::", "[::]":
+		return true
+	}
+	return false
+}
+
+// Markdown renders the report as Markdown, ready to paste into a ticket
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# Port report: %s\n\n", r.Hostname)
+	fmt.Fprintf(&sb, "- **Host:** %s\n", r.Hostname)
+	fmt.Fprintf(&sb, "- **Platform:** %s\n", r.Platform)
+	fmt.Fprintf(&sb, "- **Generated:** %s\n", r.ScanTime.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(&sb, "- **Listeners:** %d\n", len(r.Listeners))
+
+	sb.WriteString("\n## Exposure warnings\n\n")
+	if len(r.Warnings) == 0 {
+		sb.WriteString("No listeners are bound to all interfaces.\n")
+	}
+	for _, w := range r.Warnings {
+		fmt.Fprintf(&sb, "- **%s** port %d/%s (%s) %s on `%s`\n",
+			strings.ToUpper(w.Severity), w.Port, w.Protocol, describeOwner(w.Process, w.PID), w.Message, w.Address)
+	}
+
+	fmt.Fprintf(&sb, "\n## Listeners\n\n")
+	if len(r.Listeners) == 0 {
+		sb.WriteString("No listening ports found.\n")
+	} else {
+		writeMarkdownTable(&sb, reportHeaders, reportRows(r.Listeners))
+	}
+
+	for _, l := range r.Details {
+		writeMarkdownDetail(&sb, l)
+	}
+	for _, port := range r.Missing {
+		fmt.Fprintf(&sb, "\n## Port %d\n\nNot in use.\n", port)
+	}
+
+	return sb.String()
+}
+
+func writeMarkdownDetail(sb *strings.Builder, l model.Listener) {
+	fmt.Fprintf(sb, "\n## Port %d\n\n", l.Port)
+	for _, kv := range detailFields(l) {
+		fmt.Fprintf(sb, "- **%s:** %s\n", kv[0], markdownEscape(kv[1]))
+	}
+
+	if len(l.Connections) > 0 {
+		fmt.Fprintf(sb, "\n### Connections (%d established)\n\n", len(l.Connections))
+		writeMarkdownTable(sb, connectionHeaders, connectionRows(l.Connections))
+	}
+
+	if l.Stats != nil {
+		sb.WriteString("\n### Process stats\n\n")
+		writeMarkdownTable(sb, statsHeaders, [][]string{statsRow(l.Stats)})
+	}
+}
+
+func writeMarkdownTable(sb *strings.Builder, headers []string, rows [][]string) {
+	sb.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat("---|", len(headers)) + "\n")
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = markdownEscape(cell)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "`", "\\`")
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+var (
+	reportHeaders     = []string{"Port", "Proto", "Address", "PID", "User", "Process", "Conns", "Uptime"}
+	connectionHeaders = []string{"Remote address", "State", "Duration"}
+	statsHeaders      = []string{"Memory (RSS)", "CPU", "FDs", "Threads"}
+)
+
+func reportRows(listeners []model.Listener) [][]string {
+	rows := make([][]string, len(listeners))
+	for i, l := range listeners {
+		user, name, uptime := "-", "-", "-"
+		if l.Process != nil {
+			user = l.Process.User
+			name = l.Process.Name
+			if l.Process.UptimeSeconds > 0 {
+				uptime = FormatDuration(l.Process.UptimeSeconds)
+			}
+		}
+		rows[i] = []string{
+			fmt.Sprint(l.Port), l.Protocol, l.Address, fmt.Sprint(l.PID),
+			user, name, fmt.Sprint(l.ConnectionCount), uptime,
+		}
+	}
+	return rows
+}
+
+func connectionRows(connections []model.Connection) [][]string {
+	rows := make([][]string, len(connections))
+	for i, c := range connections {
+		duration := "-"
+		if c.DurationSeconds > 0 {
+			duration = FormatDuration(c.DurationSeconds)
+		}
+		rows[i] = []string{fmt.Sprintf("%s:%d", c.RemoteAddr, c.RemotePort), c.State, duration}
+	}
+	return rows
+}
+
+func statsRow(s *model.ProcessStats) []string {
+	return []string{
+		FormatBytes(s.MemoryRSS),
+		fmt.Sprintf("%.1f%%", s.CPUPercent),
+		fmt.Sprint(s.FDCount),
+		fmt.Sprint(s.ThreadCount),
+	}
+}
+
+// detailFields returns the label and value pairs describing a port
+func detailFields(l model.Listener) [][2]string {
+	fields := [][2]string{
+		{"Listening", fmt.Sprintf("%s:%d/%s", l.Address, l.Port, l.Protocol)},
+	}
+	if l.Service != nil {
+		fields = append(fields, [2]string{"Service", l.Service.Label()})
+	}
+	if f := l.Forward; f != nil {
+		target := f.Target()
+		if f.Container != nil {
+			target += " in container " + f.Container.Label()
+		}
+		if f.Process != nil {
+			target += fmt.Sprintf(" (%s, pid %d)", f.Process.Name, f.Process.PID)
+		}
+		fields = append(fields, [2]string{"Forwarded to", target})
+	}
+	if l.Process == nil {
+		return append(fields, [2]string{"Process", "permission denied or process info unavailable"})
+	}
+
+	p := l.Process
+	fields = append(fields,
+		[2]string{"Process", describeOwner(p.Name, l.PID)},
+		[2]string{"User", fmt.Sprintf("%s (uid %d)", p.User, p.UID)},
+	)
+	if len(p.Cmdline) > 0 {
+		fields = append(fields, [2]string{"Command line", strings.Join(p.Cmdline, " ")})
+	}
+	if !p.StartTime.IsZero() {
+		fields = append(fields, [2]string{"Started", p.StartTime.Format("2006-01-02 15:04:05")})
+	}
+	if p.UptimeSeconds > 0 {
+		fields = append(fields, [2]string{"Uptime", FormatDuration(p.UptimeSeconds)})
+	}
+	return fields
+}
+
+func describeOwner(name string, pid int) string {
+	if name == "" {
+		name = "unknown"
+	}
+	return fmt.Sprintf("%s, pid %d", name, pid)
+}
@@ -0,0 +1,192 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// htmlCell is a table cell with an optional sort key, used for values like
+// durations that don't sort by their text
+type htmlCell struct {
+	Text string
+	Sort string
+}
+
+type htmlTable struct {
+	Headers []string
+	Rows    [][]htmlCell
+}
+
+type htmlDetail struct {
+	Port        int
+	Fields      [][2]string
+	Connections *htmlTable
+	Stats       *htmlTable
+}
+
+// HTML renders the report as a single self-contained HTML page with
+// sortable tables
+func (r *Report) HTML() (string, error) {
+	data := struct {
+		*Report
+		Generated string
+		Table     htmlTable
+		Detail    []htmlDetail
+	}{
+		Report:    r,
+		Generated: r.ScanTime.Format("2006-01-02 15:04:05 MST"),
+		Table:     listenerTable(r.Listeners),
+	}
+
+	for _, l := range r.Details {
+		d := htmlDetail{Port: l.Port, Fields: detailFields(l)}
+		if len(l.Connections) > 0 {
+			d.Connections = &htmlTable{Headers: connectionHeaders, Rows: textRows(connectionRows(l.Connections))}
+			for i, c := range l.Connections {
+				d.Connections.Rows[i][2].Sort = fmt.Sprint(c.DurationSeconds)
+			}
+		}
+		if l.Stats != nil {
+			d.Stats = &htmlTable{Headers: statsHeaders, Rows: textRows([][]string{statsRow(l.Stats)})}
+		}
+		data.Detail = append(data.Detail, d)
+	}
+
+	var sb strings.Builder
+	if err := reportTemplate.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// listenerTable builds the listener table with numeric sort keys
+func listenerTable(listeners []model.Listener) htmlTable {
+	rows := textRows(reportRows(listeners))
+	for i, l := range listeners {
+		uptime := int64(0)
+		if l.Process != nil {
+			uptime = l.Process.UptimeSeconds
+		}
+		rows[i][7].Sort = fmt.Sprint(uptime)
+	}
+	return htmlTable{Headers: reportHeaders, Rows: rows}
+}
+
+func textRows(rows [][]string) [][]htmlCell {
+	out := make([][]htmlCell, len(rows))
+	for i, row := range rows {
+		out[i] = make([]htmlCell, len(row))
+		for j, text := range row {
+			out[i][j] = htmlCell{Text: text}
+		}
+	}
+	return out
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Port report: {{.Hostname}}</title>
+<style>
+body { font: 14px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1100px; padding: 0 1em; color: #1f2328; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.25em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; margin-top: 2em; }
+table { border-collapse: collapse; width: 100%; margin: .5em 0; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; }
+th { background: #f6f8fa; }
+table.sortable th { cursor: pointer; user-select: none; }
+table.sortable th[data-dir="asc"]::after { content: " ▲"; }
+table.sortable th[data-dir="desc"]::after { content: " ▼"; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 2px 1em; }
+dt { font-weight: 600; }
+dd { margin: 0; font-family: ui-monospace, Menlo, monospace; }
+.meta { color: #57606a; }
+.high { color: #cf222e; font-weight: 600; }
+.warning { color: #9a6700; font-weight: 600; }
+</style>
+</head>
+<body>
+<h1>Port report: {{.Hostname}}</h1>
+<p class="meta">Host {{.Hostname}} · {{.Platform}} · generated {{.Generated}} · {{len .Listeners}} listeners</p>
+
+<h2>Exposure warnings</h2>
+{{- if .Warnings}}
+<ul>
+{{- range .Warnings}}
+<li><span class="{{.Severity}}">{{.Severity}}</span> port {{.Port}}/{{.Protocol}} ({{if .Process}}{{.Process}}{{else}}unknown{{end}}, pid {{.PID}}) {{.Message}} on <code>{{.Address}}</code></li>
+{{- end}}
+</ul>
+{{- else}}
+<p>No listeners are bound to all interfaces.</p>
+{{- end}}
+
+<h2>Listeners</h2>
+{{- if .Listeners}}
+{{template "table" .Table}}
+{{- else}}
+<p>No listening ports found.</p>
+{{- end}}
+
+{{- range .Detail}}
+<h2>Port {{.Port}}</h2>
+<dl>
+{{- range .Fields}}
+<dt>{{index . 0}}</dt><dd>{{index . 1}}</dd>
+{{- end}}
+</dl>
+{{- with .Connections}}
+<h3>Connections ({{len .Rows}} established)</h3>
+{{template "table" .}}
+{{- end}}
+{{- with .Stats}}
+<h3>Process stats</h3>
+{{template "table" .}}
+{{- end}}
+{{- end}}
+
+{{- range .Missing}}
+<h2>Port {{.}}</h2>
+<p>Not in use.</p>
+{{- end}}
+
+<script>
+document.querySelectorAll("table.sortable th").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table");
+    var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+    var dir = th.dataset.dir === "asc" ? "desc" : "asc";
+    table.querySelectorAll("th").forEach(function (h) { delete h.dataset.dir; });
+    th.dataset.dir = dir;
+
+    var key = function (row) {
+      var cell = row.children[index];
+      return cell.dataset.sort !== undefined ? cell.dataset.sort : cell.textContent;
+    };
+    var body = table.tBodies[0];
+    var rows = Array.prototype.slice.call(body.rows);
+    rows.sort(function (a, b) {
+      var x = key(a), y = key(b);
+      var nx = parseFloat(x), ny = parseFloat(y);
+      var c = !isNaN(nx) && !isNaN(ny) && String(nx) === x && String(ny) === y
+        ? nx - ny
+        : x.localeCompare(y, undefined, { numeric: true });
+      return dir === "asc" ? c : -c;
+    });
+    rows.forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+{{define "table"}}<table class="sortable">
+<thead><tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td{{if .Sort}} data-sort="{{.Sort}}"{{end}}>{{.Text}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>{{end}}`))
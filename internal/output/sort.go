@@ -0,0 +1,187 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// sortField is a listener value that listings can be sorted by
+type sortField struct {
+	desc    bool // Natural direction is descending, e.g. busiest first
+	stats   bool // Reads process stats, which the scanner only collects on request
+	compare func(a, b model.Listener) int
+}
+
+var sortFields = map[string]sortField{
+	"port":    {compare: func(a, b model.Listener) int { return cmp.Compare(a.Port, b.Port) }},
+	"pid":     {compare: func(a, b model.Listener) int { return cmp.Compare(a.PID, b.PID) }},
+	"proto":   {compare: func(a, b model.Listener) int { return cmp.Compare(a.Protocol, b.Protocol) }},
+	"address": {compare: func(a, b model.Listener) int { return cmp.Compare(a.Address, b.Address) }},
+	"user": {compare: func(a, b model.Listener) int {
+		return cmp.Compare(processValueOf(a, func(p *model.Process) string { return p.User }), processValueOf(b, func(p *model.Process) string { return p.User }))
+	}},
+	"command": {compare: func(a, b model.Listener) int { return cmp.Compare(commandName(a), commandName(b)) }},
+	"conns":   {desc: true, compare: func(a, b model.Listener) int { return cmp.Compare(a.ConnectionCount, b.ConnectionCount) }},
+	"uptime": {desc: true, compare: func(a, b model.Listener) int {
+		return cmp.Compare(processValueOf(a, func(p *model.Process) int64 { return p.UptimeSeconds }), processValueOf(b, func(p *model.Process) int64 { return p.UptimeSeconds }))
+	}},
+	"rss": {desc: true, stats: true, compare: func(a, b model.Listener) int {
+		return cmp.Compare(statsValueOf(a, func(s *model.ProcessStats) int64 { return s.MemoryRSS }), statsValueOf(b, func(s *model.ProcessStats) int64 { return s.MemoryRSS }))
+	}},
+	"cpu": {desc: true, stats: true, compare: func(a, b model.Listener) int {
+		return cmp.Compare(statsValueOf(a, func(s *model.ProcessStats) float64 { return s.CPUPercent }), statsValueOf(b, func(s *model.ProcessStats) float64 { return s.CPUPercent }))
+	}},
+	"fds": {desc: true, stats: true, compare: func(a, b model.Listener) int {
+		return cmp.Compare(statsValueOf(a, func(s *model.ProcessStats) int { return s.FDCount }), statsValueOf(b, func(s *model.ProcessStats) int { return s.FDCount }))
+	}},
+}
+
+// sortAliases maps alternative names to sort keys
+var sortAliases = map[string]string{
+	"protocol":    "proto",
+	"addr":        "address",
+	"cmd":         "command",
+	"connections": "conns",
+	"memory":      "rss",
+	"mem":         "rss",
+}
+
+// tieBreakers order listeners that all chosen keys consider equal, so
+// rows keep their place between watch refreshes
+var tieBreakers = []string{"port", "proto", "address"}
+
+// SortKey is one key of a sort order
+type SortKey struct {
+	Name string
+	Desc bool
+}
+
+// SortOrder is a parsed --sort value
+type SortOrder []SortKey
+
+// ParseSort parses a comma-separated sort order such as
+// "user,-conns,port". A "-" prefix sorts a key descending and "+"
+// ascending; a bare key uses its natural direction, which is descending
+// for conns, uptime, rss, cpu and fds (biggest first) and ascending for the
+// rest.
+func ParseSort(spec string) (SortOrder, error) {
+	var order SortOrder
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		name := strings.TrimLeft(part, "+-")
+		if alias, ok := sortAliases[name]; ok {
+			name = alias
+		}
+		field, ok := sortFields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort key: %s (available: %s)", name, strings.Join(SortKeyNames(), ", "))
+		}
+
+		desc := field.desc
+		switch part[0] {
+		case '-':
+			desc = true
+		case '+':
+			desc = false
+		}
+		order = append(order, SortKey{Name: name, Desc: desc})
+	}
+
+	if len(order) == 0 {
+		return nil, fmt.Errorf("no sort keys given (available: %s)", strings.Join(SortKeyNames(), ", "))
+	}
+	return order, nil
+}
+
+// SortKeyNames returns the names of all sort keys, sorted
+func SortKeyNames() []string {
+	names := make([]string, 0, len(sortFields))
+	for name := range sortFields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NeedsStats reports whether the order reads process stats
+func (o SortOrder) NeedsStats() bool {
+	for _, k := range o {
+		if sortFields[k.Name].stats {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the order like its --sort value, marking keys that
+// differ from their natural direction
+func (o SortOrder) String() string {
+	parts := make([]string, len(o))
+	for i, k := range o {
+		switch {
+		case k.Desc == sortFields[k.Name].desc:
+			parts[i] = k.Name
+		case k.Desc:
+			parts[i] = "-" + k.Name
+		default:
+			parts[i] = "+" + k.Name
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Sort sorts listeners by each key in turn, then by port, protocol and
+// address. The sort is stable.
+func (o SortOrder) Sort(listeners []model.Listener) {
+	slices.SortStableFunc(listeners, func(a, b model.Listener) int {
+		for _, k := range o {
+			c := sortFields[k.Name].compare(a, b)
+			if k.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		for _, name := range tieBreakers {
+			if c := sortFields[name].compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// SortListeners sorts listeners by a --sort value such as "user,-conns".
+// An invalid value sorts by port.
+func SortListeners(listeners []model.Listener, by string) {
+	order, err := ParseSort(by)
+	if err != nil {
+		order = SortOrder{{Name: "port"}}
+	}
+	order.Sort(listeners)
+}
+
+func processValueOf[T cmp.Ordered](l model.Listener, get func(p *model.Process) T) T {
+	var zero T
+	if l.Process == nil {
+		return zero
+	}
+	return get(l.Process)
+}
+
+func statsValueOf[T cmp.Ordered](l model.Listener, get func(s *model.ProcessStats) T) T {
+	var zero T
+	if l.Stats == nil {
+		return zero
+	}
+	return get(l.Stats)
+}
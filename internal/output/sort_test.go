@@ -0,0 +1,137 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		spec  string
+		want  string // String() of the order
+		keys  []SortKey
+		stats bool
+	}{
+		{"port", "port", []SortKey{{"port", false}}, false},
+		{"-port", "-port", []SortKey{{"port", true}}, false},
+		{"conns", "conns", []SortKey{{"conns", true}}, false},
+		{"+conns", "+conns", []SortKey{{"conns", false}}, false},
+		{"user,-conns,port", "user,conns,port", []SortKey{{"user", false}, {"conns", true}, {"port", false}}, false},
+		{" Protocol , ADDR ", "proto,address", []SortKey{{"proto", false}, {"address", false}}, false},
+		{"cmd,connections", "command,conns", []SortKey{{"command", false}, {"conns", true}}, false},
+		{"mem", "rss", []SortKey{{"rss", true}}, true},
+		{"+memory", "+rss", []SortKey{{"rss", false}}, true},
+		{"port,,cpu", "port,cpu", []SortKey{{"port", false}, {"cpu", true}}, true},
+		{"uptime,fds", "uptime,fds", []SortKey{{"uptime", true}, {"fds", true}}, true},
+	}
+	for _, tt := range tests {
+		order, err := ParseSort(tt.spec)
+		if err != nil {
+			t.Errorf("ParseSort(%q): %v", tt.spec, err)
+			continue
+		}
+		if fmt.Sprint(order) != fmt.Sprint(SortOrder(tt.keys)) {
+			t.Errorf("ParseSort(%q) = %v, want %v", tt.spec, order, tt.keys)
+		}
+		if order.String() != tt.want {
+			t.Errorf("ParseSort(%q).String() = %q, want %q", tt.spec, order.String(), tt.want)
+		}
+		if order.NeedsStats() != tt.stats {
+			t.Errorf("ParseSort(%q).NeedsStats() = %v, want %v", tt.spec, order.NeedsStats(), tt.stats)
+		}
+	}
+}
+
+func TestParseSortErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"", "no sort keys given"},
+		{" , ", "no sort keys given"},
+		{"port,bogus", "unknown sort key: bogus"},
+		{"-", "unknown sort key: "},
+	}
+	for _, tt := range tests {
+		_, err := ParseSort(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseSort(%q) = %v, want an error containing %q", tt.spec, err, tt.want)
+		}
+	}
+}
+
+// sortListener builds a listener for sort tests
+func sortListener(port int, proto, addr, user string, conns int, rss int64) model.Listener {
+	l := model.Listener{Port: port, Protocol: proto, Address: addr, ConnectionCount: conns}
+	if user != "" {
+		l.Process = &model.Process{User: user}
+	}
+	if rss > 0 {
+		l.Stats = &model.ProcessStats{MemoryRSS: rss}
+	}
+	return l
+}
+
+// sortedKeys renders listeners as "port/proto@address" for comparison
+func sortedKeys(listeners []model.Listener) string {
+	keys := make([]string, len(listeners))
+	for i, l := range listeners {
+		keys[i] = fmt.Sprintf("%d/%s@%s", l.Port, l.Protocol, l.Address)
+	}
+	return strings.Join(keys, " ")
+}
+
+func TestSort(t *testing.T) {
+	listeners := []model.Listener{
+		sortListener(8080, "tcp", "::", "bob", 1, 100),
+		sortListener(53, "udp", "0.0.0.0", "root", 0, 0),
+		sortListener(3000, "tcp", "127.0.0.1", "bob", 5, 300),
+		sortListener(53, "tcp", "0.0.0.0", "root", 0, 0),
+		sortListener(3000, "tcp", "::1", "alice", 5, 200),
+		sortListener(9000, "tcp", "::", "", 2, 0),
+	}
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"port", "53/tcp@0.0.0.0 53/udp@0.0.0.0 3000/tcp@127.0.0.1 3000/tcp@::1 8080/tcp@:: 9000/tcp@::"},
+		{"-port", "9000/tcp@:: 8080/tcp@:: 3000/tcp@127.0.0.1 3000/tcp@::1 53/tcp@0.0.0.0 53/udp@0.0.0.0"},
+		{"conns", "3000/tcp@127.0.0.1 3000/tcp@::1 9000/tcp@:: 8080/tcp@:: 53/tcp@0.0.0.0 53/udp@0.0.0.0"},
+		{"user,-conns", "9000/tcp@:: 3000/tcp@::1 3000/tcp@127.0.0.1 8080/tcp@:: 53/tcp@0.0.0.0 53/udp@0.0.0.0"},
+		{"rss", "3000/tcp@127.0.0.1 3000/tcp@::1 8080/tcp@:: 53/tcp@0.0.0.0 53/udp@0.0.0.0 9000/tcp@::"},
+		{"proto,-port", "9000/tcp@:: 8080/tcp@:: 3000/tcp@127.0.0.1 3000/tcp@::1 53/tcp@0.0.0.0 53/udp@0.0.0.0"},
+	}
+	for _, tt := range tests {
+		order, err := ParseSort(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseSort(%q): %v", tt.spec, err)
+		}
+
+		// Sorting must not depend on the input order
+		backwards := slices.Clone(listeners)
+		slices.Reverse(backwards)
+		for _, input := range [][]model.Listener{listeners, backwards} {
+			sorted := slices.Clone(input)
+			order.Sort(sorted)
+			if got := sortedKeys(sorted); got != tt.want {
+				t.Errorf("Sort(%q) =\n%s\nwant\n%s", tt.spec, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestSortListenersInvalidFallsBackToPort(t *testing.T) {
+	listeners := []model.Listener{
+		sortListener(8080, "tcp", "::", "", 0, 0),
+		sortListener(22, "tcp", "::", "", 0, 0),
+	}
+	SortListeners(listeners, "bogus")
+	if got := sortedKeys(listeners); got != "22/tcp@:: 8080/tcp@::" {
+		t.Errorf("SortListeners(bogus) = %s", got)
+	}
+}
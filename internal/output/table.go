@@ -3,6 +3,7 @@ package output
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/tasnimzotder/portman/internal/model"
 )
@@ -10,10 +11,12 @@ import (
 type TableFormatter struct {
 	NoHeader bool
 	SortBy   string
+	Columns  []Column // Columns to show (default: DefaultColumns)
 }
 
 func NewTableFormatter() *TableFormatter {
-	return &TableFormatter{}
+	columns, _ := LookupColumns(DefaultColumns)
+	return &TableFormatter{Columns: columns}
 }
 
 func (f *TableFormatter) Format(listeners []model.Listener) string {
@@ -21,53 +24,47 @@ func (f *TableFormatter) Format(listeners []model.Listener) string {
 		return "No listening ports found."
 	}
 
-	var sb strings.Builder
+	headers := Headers(f.Columns)
+	rows := make([][]string, len(listeners))
+	for i, l := range listeners {
+		rows[i] = Cells(f.Columns, l)
+	}
 
-	if !f.NoHeader {
-		sb.WriteString(fmt.Sprintf(
-			"%-8s %-8s %-8s %-10s %-24s %-7s %s\n",
-			"PORT", "PROTO", "PID", "USER", "COMMAND", "CONNS", "UPTIME",
-		))
+	// Size each column to its widest value
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
 	}
 
-	for _, l := range listeners {
-		pid := "-"
-		user := "-"
-		command := "-"
-		uptime := "-"
+	var sb strings.Builder
 
-		if l.PID > 0 {
-			pid = fmt.Sprintf("%d", l.PID)
-		}
+	if !f.NoHeader {
+		sb.WriteString(formatRow(headers, widths))
+	}
 
-		if l.Process != nil {
-			if l.Process.User != "" {
-				user = l.Process.User
-			}
+	for _, row := range rows {
+		sb.WriteString(formatRow(row, widths))
+	}
 
-			if l.Process.Command != "" {
-				command = truncate(l.Process.Command, 24)
-			} else if l.Process.Name != "" {
-				command = truncate(l.Process.Name, 24)
-			}
+	return sb.String()
+}
 
-			if l.Process.UptimeSeconds > 0 {
-				uptime = FormatDuration(l.Process.UptimeSeconds)
-			}
+// formatRow pads cells to the column widths, leaving the last unpadded
+func formatRow(cells []string, widths []int) string {
+	var sb strings.Builder
+	for i, cell := range cells {
+		if i == len(cells)-1 {
+			sb.WriteString(cell)
+		} else {
+			sb.WriteString(fmt.Sprintf("%-*s  ", widths[i], cell))
 		}
-
-		sb.WriteString(fmt.Sprintf(
-			"%-8d %-8s %-8s %-10s %-24s %-7d %s\n",
-			l.Port,
-			l.Protocol,
-			pid,
-			truncate(user, 10),
-			command,
-			l.ConnectionCount,
-			uptime,
-		))
 	}
-
+	sb.WriteString("\n")
 	return sb.String()
 }
 
@@ -81,6 +78,18 @@ func (f *TableFormatter) FormatDetail(l *model.Listener) string {
 	sb.WriteString(fmt.Sprintf("Port %d\n", l.Port))
 	sb.WriteString("═══════════════════════════════════════════════════════════════\n\n")
 
+	if l.Service != nil {
+		sb.WriteString("Service\n")
+		sb.WriteString(fmt.Sprintf("  Name:        %s\n", l.Service.Name))
+		if l.Service.Owner != "" {
+			sb.WriteString(fmt.Sprintf("  Owner:       %s\n", l.Service.Owner))
+		}
+		if l.Service.Description != "" {
+			sb.WriteString(fmt.Sprintf("  About:       %s\n", l.Service.Description))
+		}
+		sb.WriteString("\n")
+	}
+
 	sb.WriteString("Process\n")
 	if l.Process != nil {
 		sb.WriteString(fmt.Sprintf("  PID:         %d\n", l.Process.PID))
@@ -96,10 +105,53 @@ func (f *TableFormatter) FormatDetail(l *model.Listener) string {
 		} else if l.Process.UptimeSeconds > 0 {
 			sb.WriteString(fmt.Sprintf("  Uptime:      %s\n", FormatDuration(l.Process.UptimeSeconds)))
 		}
+	} else if l.Forward != nil && l.PID <= 0 {
+		sb.WriteString("  (none: the kernel forwards this port, e.g. with an iptables DNAT rule)\n")
 	} else {
 		sb.WriteString("  (permission denied or process info unavailable)\n")
 	}
 
+	if c := containerOf(l); c != nil {
+		sb.WriteString("\nContainer\n")
+		id := c.ShortID()
+		if c.Runtime != "" {
+			id += " (" + c.Runtime + ")"
+		}
+		sb.WriteString(fmt.Sprintf("  ID:          %s\n", id))
+		if c.Name != "" {
+			sb.WriteString(fmt.Sprintf("  Name:        %s\n", c.Name))
+		}
+		if c.Image != "" {
+			sb.WriteString(fmt.Sprintf("  Image:       %s\n", c.Image))
+		}
+		if c.PodUID != "" {
+			sb.WriteString(fmt.Sprintf("  Pod UID:     %s\n", c.PodUID))
+		}
+	}
+
+	if p := l.Process; p != nil && (p.Cwd != "" || p.TTY != "" || p.Session != nil || len(p.Parents) > 0 || p.Project != nil) {
+		sb.WriteString("\nLaunched from\n")
+		if p.Cwd != "" {
+			sb.WriteString(fmt.Sprintf("  Directory:   %s\n", p.Cwd))
+		}
+		if p.Project != nil {
+			sb.WriteString(fmt.Sprintf("  Project:     %s (%s in %s)\n", p.Project.Name, p.Project.Marker, p.Project.Root))
+		}
+		if p.TTY != "" {
+			sb.WriteString(fmt.Sprintf("  TTY:         %s\n", p.TTY))
+		}
+		if p.Session != nil {
+			sb.WriteString(fmt.Sprintf("  Session:     %s\n", p.Session.Label()))
+		}
+		if len(p.Parents) > 0 {
+			chain := make([]string, len(p.Parents))
+			for i, parent := range p.Parents {
+				chain[i] = fmt.Sprintf("%s (%d)", parent.Name, parent.PID)
+			}
+			sb.WriteString(fmt.Sprintf("  Parents:     %s\n", strings.Join(chain, " ← ")))
+		}
+	}
+
 	sb.WriteString("\n")
 
 	sb.WriteString("Listening\n")
@@ -110,6 +162,33 @@ func (f *TableFormatter) FormatDetail(l *model.Listener) string {
 	sb.WriteString(fmt.Sprintf("  Address:     %s:%d\n", addr, l.Port))
 	sb.WriteString(fmt.Sprintf("  Protocol:    %s\n", strings.ToUpper(l.Protocol)))
 
+	if f := l.Forward; f != nil {
+		sb.WriteString("\nForwarded to\n")
+		if l.PID > 0 {
+			sb.WriteString(fmt.Sprintf("  Hop 1:       %s:%d → %s (pid %d)\n", l.Address, l.Port, commandName(*l), l.PID))
+		} else {
+			sb.WriteString(fmt.Sprintf("  Hop 1:       %s:%d (published by %s, no proxy process)\n", l.Address, l.Port, f.Via))
+		}
+		target := f.Target()
+		if f.Process != nil {
+			target += fmt.Sprintf(" → %s (pid %d)", f.Process.Name, f.Process.PID)
+		}
+		sb.WriteString(fmt.Sprintf("  Hop 2:       %s\n", target))
+		if c := f.Container; c != nil {
+			if c.Name != "" {
+				sb.WriteString(fmt.Sprintf("  Container:   %s (%s)\n", c.Name, c.ShortID()))
+			} else {
+				sb.WriteString(fmt.Sprintf("  Container:   %s\n", c.ShortID()))
+			}
+			if c.Image != "" {
+				sb.WriteString(fmt.Sprintf("  Image:       %s\n", c.Image))
+			}
+		}
+		if f.Process != nil && len(f.Process.Cmdline) > 0 {
+			sb.WriteString(fmt.Sprintf("  Command:     %s\n", strings.Join(f.Process.Cmdline, " ")))
+		}
+	}
+
 	if len(l.Connections) > 0 {
 		sb.WriteString(fmt.Sprintf("\nConnections (%d established)\n", len(l.Connections)))
 		sb.WriteString(fmt.Sprintf("  %-42s %-14s %s\n", "REMOTE ADDRESS", "STATE", "DURATION"))
@@ -133,3 +212,11 @@ func (f *TableFormatter) FormatDetail(l *model.Listener) string {
 
 	return sb.String()
 }
+
+// containerOf returns the container of a listener's process, or nil
+func containerOf(l *model.Listener) *model.Container {
+	if l.Process == nil {
+		return nil
+	}
+	return l.Process.Container
+}
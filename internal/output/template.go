@@ -0,0 +1,182 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// TemplateFormatter renders each listener with a Go text/template, like
+// `docker ps --format`
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+// TemplateFuncs are the helper functions available to output templates
+var TemplateFuncs = template.FuncMap{
+	"bytes":    func(v any) string { return FormatBytes(toInt64(v)) },
+	"duration": func(v any) string { return FormatDuration(toInt64(v)) },
+	"join":     func(elems []string, sep string) string { return strings.Join(elems, sep) },
+	"upper":    strings.ToUpper,
+}
+
+// NewTemplateFormatter parses an output template
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	tmpl, err := template.New("output").Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+// UsesStats reports whether the template reads process stats, which the
+// scanner only collects on request. Any field or variable access named Stats
+// counts, whatever it is reached through, such as $l.Stats after {{$l := .}}.
+// A template that only prints a whole listener doesn't; it needs --stats.
+func (f *TemplateFormatter) UsesStats() bool {
+	for _, t := range f.tmpl.Templates() {
+		if t.Tree != nil && readsField(t.Tree.Root, "Stats") {
+			return true
+		}
+	}
+	return false
+}
+
+// readsField reports whether any field access in the parse tree under n
+// names field
+func readsField(n parse.Node, field string) bool {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Nodes {
+			if readsField(c, field) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return readsField(n.Pipe, field)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, c := range n.Cmds {
+			if readsField(c, field) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if readsField(arg, field) {
+				return true
+			}
+		}
+	case *parse.FieldNode:
+		return slices.Contains(n.Ident, field)
+	case *parse.VariableNode:
+		return slices.Contains(n.Ident[1:], field)
+	case *parse.ChainNode:
+		return slices.Contains(n.Field, field) || readsField(n.Node, field)
+	case *parse.IfNode:
+		return readsBranch(&n.BranchNode, field)
+	case *parse.RangeNode:
+		return readsBranch(&n.BranchNode, field)
+	case *parse.WithNode:
+		return readsBranch(&n.BranchNode, field)
+	case *parse.TemplateNode:
+		return readsField(n.Pipe, field)
+	}
+	return false
+}
+
+func readsBranch(b *parse.BranchNode, field string) bool {
+	return readsField(b.Pipe, field) || readsField(b.List, field) || readsField(b.ElseList, field)
+}
+
+// Format renders the template once per listener, each on its own line
+func (f *TemplateFormatter) Format(listeners []model.Listener) (string, error) {
+	var sb strings.Builder
+	for _, l := range listeners {
+		if err := f.execute(&sb, l); err != nil {
+			return "", err
+		}
+	}
+	return sb.String(), nil
+}
+
+// FormatSingle renders the template for a port's detail, connections and
+// stats included. A port that isn't in use renders nothing, as there is no
+// listener to execute the template against; callers report it instead.
+func (f *TemplateFormatter) FormatSingle(l *model.Listener) (string, error) {
+	if l == nil {
+		return "", nil
+	}
+	var sb strings.Builder
+	if err := f.execute(&sb, *l); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func (f *TemplateFormatter) execute(sb *strings.Builder, l model.Listener) error {
+	var line strings.Builder
+	if err := f.tmpl.Execute(&line, withDefaults(l)); err != nil {
+		return fmt.Errorf("template: %w", err)
+	}
+	sb.WriteString(line.String())
+	if !strings.HasSuffix(line.String(), "\n") {
+		sb.WriteString("\n")
+	}
+	return nil
+}
+
+// withDefaults returns a copy of l with every optional struct set, so that
+// missing information renders as empty values rather than failing on a nil
+// pointer. The listener's own structs are left untouched.
+func withDefaults(l model.Listener) model.Listener {
+	l.Service = orZero(l.Service)
+	l.Stats = orZero(l.Stats)
+
+	p := *orZero(l.Process)
+	p.Container = orZero(p.Container)
+	p.Session = orZero(p.Session)
+	p.Project = orZero(p.Project)
+	l.Process = &p
+
+	fw := *orZero(l.Forward)
+	fw.Container = orZero(fw.Container)
+	fw.Process = orZero(fw.Process)
+	l.Forward = &fw
+	return l
+}
+
+// orZero returns p, or a pointer to a zero value if p is nil
+func orZero[T any](p *T) *T {
+	if p == nil {
+		return new(T)
+	}
+	return p
+}
+
+// toInt64 converts the numeric fields templates pass to helpers
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case uint64:
+		return int64(n)
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
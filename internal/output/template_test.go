@@ -0,0 +1,53 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+func TestTemplateUsesStats(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"{{.Port}} {{.Process.Name}}", false},
+		{"{{bytes .Stats.MemoryRSS}}", true},
+		{"{{$l := .}}{{$l.Stats.CPUPercent}}", true},
+		{"{{with .Stats}}{{.FDCount}}{{end}}", true},
+		{"{{if .Process}}{{else}}{{$.Stats}}{{end}}", true},
+		{"{{range $c := .Connections}}{{$c.State}}{{end}}", false},
+		{`{{define "mem"}}{{.Stats.MemoryRSS}}{{end}}{{template "mem" .}}`, true},
+		{"{{(index .Connections 0).State}}", false},
+		{"{{.Stats | printf \"%v\"}}", true},
+		{"Stats: {{.PID}}", false},
+	}
+	for _, tt := range tests {
+		f, err := NewTemplateFormatter(tt.text)
+		if err != nil {
+			t.Fatalf("NewTemplateFormatter(%q): %v", tt.text, err)
+		}
+		if got := f.UsesStats(); got != tt.want {
+			t.Errorf("UsesStats() for %q = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateMissingFields(t *testing.T) {
+	f, err := NewTemplateFormatter("{{.Port}} {{.Forward.Container.Name}}{{.Forward.Process.PID}} {{.Process.Container.Name}}{{.Process.Session.Name}}{{.Process.Project.Name}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := model.Listener{Port: 8080, Process: &model.Process{Name: "node"}}
+
+	got, err := f.Format([]model.Listener{l})
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if want := "8080 0 \n"; got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+	if l.Forward != nil || l.Process.Container != nil || l.Process.Project != nil {
+		t.Error("Format filled in the listener it was given")
+	}
+}
@@ -0,0 +1,200 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// YAMLFormatter writes the same document as the JSON formatter: the
+// ScanResult is encoded as JSON and re-emitted as block-style YAML, keeping
+// the key order, nesting, and nulls of the JSON output.
+type YAMLFormatter struct{}
+
+func NewYAMLFormatter() *YAMLFormatter {
+	return &YAMLFormatter{}
+}
+
+func (f *YAMLFormatter) Format(listeners []model.Listener) (string, error) {
+	return marshalYAML(NewScanResult(listeners))
+}
+
+// FormatGroups writes the envelope with the listeners under their
+// --group-by groups, as JSONFormatter.FormatGroups does
+func (f *YAMLFormatter) FormatGroups(groups []model.ListenerGroup, by string) (string, error) {
+	return marshalYAML(newGroupedResult(groups, by))
+}
+
+func (f *YAMLFormatter) FormatSingle(listener *model.Listener) (string, error) {
+	if listener == nil {
+		return f.Format(nil)
+	}
+	return f.Format([]model.Listener{*listener})
+}
+
+// yamlMapping is a JSON object with its keys in document order
+type yamlMapping struct {
+	keys   []string
+	values []any
+}
+
+// marshalYAML writes v as YAML by way of its JSON encoding
+func marshalYAML(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	doc, err := decodeYAMLValue(dec)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	writeYAMLValue(&sb, doc, 0)
+	return sb.String(), nil
+}
+
+// decodeYAMLValue reads the next JSON value, keeping object keys in order
+func decodeYAMLValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := &yamlMapping{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeYAMLValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.keys = append(m.keys, key.(string))
+			m.values = append(m.values, value)
+		}
+		_, err := dec.Token() // }
+		return m, err
+	case json.Delim('['):
+		list := []any{}
+		for dec.More() {
+			value, err := decodeYAMLValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token() // ]
+		return list, err
+	}
+	return tok, nil
+}
+
+// writeYAMLValue writes a document root: a mapping, a sequence, or a
+// scalar on its own line
+func writeYAMLValue(sb *strings.Builder, v any, indent int) {
+	switch v := v.(type) {
+	case *yamlMapping:
+		if len(v.keys) > 0 {
+			writeYAMLMapping(sb, v, indent, "")
+			return
+		}
+	case []any:
+		if len(v) > 0 {
+			writeYAMLSequence(sb, v, indent)
+			return
+		}
+	}
+	sb.WriteString(yamlScalar(v) + "\n")
+}
+
+// writeYAMLMapping writes a mapping indented by indent spaces. When first
+// is set, it replaces the indentation of the first line, e.g. with "- ".
+func writeYAMLMapping(sb *strings.Builder, m *yamlMapping, indent int, first string) {
+	pad := strings.Repeat(" ", indent)
+	for i, key := range m.keys {
+		prefix := pad
+		if i == 0 && first != "" {
+			prefix = first
+		}
+		fmt.Fprintf(sb, "%s%s:", prefix, yamlScalar(key))
+		writeYAMLChild(sb, m.values[i], indent+2)
+	}
+}
+
+// writeYAMLSequence writes a sequence with its dashes indented by indent
+// spaces
+func writeYAMLSequence(sb *strings.Builder, list []any, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for _, item := range list {
+		if m, ok := item.(*yamlMapping); ok && len(m.keys) > 0 {
+			writeYAMLMapping(sb, m, indent+2, pad+"- ")
+			continue
+		}
+		sb.WriteString(pad + "-")
+		writeYAMLChild(sb, item, indent+2)
+	}
+}
+
+// writeYAMLChild completes a line ending in "key:" or "-" with a value:
+// scalars and empty collections inline, others on the following lines
+func writeYAMLChild(sb *strings.Builder, v any, indent int) {
+	switch v := v.(type) {
+	case *yamlMapping:
+		if len(v.keys) > 0 {
+			sb.WriteString("\n")
+			writeYAMLMapping(sb, v, indent, "")
+			return
+		}
+	case []any:
+		if len(v) > 0 {
+			sb.WriteString("\n")
+			writeYAMLSequence(sb, v, indent)
+			return
+		}
+	}
+	sb.WriteString(" " + yamlScalar(v) + "\n")
+}
+
+// yamlPlain matches strings that are safe to write unquoted
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./+-]*$`)
+
+// yamlReserved are plain words YAML would read as something other than a
+// string
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true,
+	"on": true, "off": true, "null": true, "y": true, "n": true,
+}
+
+// yamlScalar renders a decoded JSON scalar, or an empty collection, as a
+// YAML scalar
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if yamlPlain.MatchString(v) && !yamlReserved[strings.ToLower(v)] {
+			return v
+		}
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case *yamlMapping:
+		return "{}"
+	case []any:
+		return "[]"
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
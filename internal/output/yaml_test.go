@@ -0,0 +1,194 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	result := model.ScanResult{
+		SchemaVersion: 7,
+		Listeners: []model.Listener{
+			{
+				Port:     8080,
+				Protocol: "tcp",
+				Address:  "127.0.0.1",
+				PID:      812,
+				Process: &model.Process{
+					PID:       812,
+					Name:      "node",
+					Command:   "node",
+					Cmdline:   []string{"node", "--port", "8080", "server: main.js"},
+					User:      "bob",
+					UID:       501,
+					StartTime: start,
+					Parents:   []model.ProcessRef{{PID: 1, Name: "init"}},
+				},
+				Connections: []model.Connection{
+					{LocalAddr: "127.0.0.1", LocalPort: 8080, RemoteAddr: "127.0.0.1", RemotePort: 51234, State: "ESTABLISHED"},
+				},
+				ConnectionCount: 1,
+				Stats:           &model.ProcessStats{MemoryRSS: 1024, CPUPercent: 1.5, FDCount: 12, ThreadCount: 3},
+			},
+			{Port: 53, Protocol: "udp", Address: "*", Process: &model.Process{Name: "dns"}},
+		},
+		ScanTime: start,
+		Platform: "linux",
+		Hostname: "yes",
+	}
+
+	got, err := marshalYAML(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `schemaVersion: 7
+listeners:
+  - port: 8080
+    protocol: tcp
+    address: "127.0.0.1"
+    pid: 812
+    process:
+      pid: 812
+      name: node
+      command: node
+      cmdline:
+        - node
+        - "--port"
+        - "8080"
+        - "server: main.js"
+      user: bob
+      uid: 501
+      startTime: "2026-01-02T03:04:05Z"
+      uptimeSeconds: 0
+      parents:
+        - pid: 1
+          name: init
+    connections:
+      - localAddr: "127.0.0.1"
+        localPort: 8080
+        remoteAddr: "127.0.0.1"
+        remotePort: 51234
+        state: ESTABLISHED
+    connectionCount: 1
+    stats:
+      memoryRSS: 1024
+      cpuPercent: 1.5
+      fdCount: 12
+      threadCount: 3
+  - port: 53
+    protocol: udp
+    address: "*"
+    pid: 0
+    process:
+      pid: 0
+      name: dns
+      command: ""
+      cmdline: null
+      user: ""
+      uid: 0
+      startTime: "0001-01-01T00:00:00Z"
+      uptimeSeconds: 0
+    connectionCount: 0
+scanTime: "2026-01-02T03:04:05Z"
+platform: linux
+hostname: "yes"
+`
+	if got != want {
+		t.Errorf("marshalYAML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestYAMLMatchesJSON checks that YAML and JSON output carry the same
+// top-level keys in the same order, groups included
+func TestYAMLMatchesJSON(t *testing.T) {
+	listeners := []model.Listener{
+		{Port: 3000, Protocol: "tcp", Address: "::", PID: 10, Process: &model.Process{PID: 10, Name: "node", User: "bob"}},
+		{Port: 5432, Protocol: "tcp", Address: "::", PID: 20, Process: &model.Process{PID: 20, Name: "postgres", User: "pg"}},
+	}
+	groups := GroupListeners(listeners, "user")
+
+	js, err := NewJSONFormatter(false).FormatGroups(groups, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := NewYAMLFormatter().FormatGroups(groups, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(js))
+	if _, err := dec.Token(); err != nil {
+		t.Fatal(err)
+	}
+	var jsonKeys []string
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		jsonKeys = append(jsonKeys, key.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var yamlKeys []string
+	for _, line := range strings.Split(y, "\n") {
+		if key, _, ok := strings.Cut(line, ":"); ok && !strings.HasPrefix(line, " ") {
+			yamlKeys = append(yamlKeys, key)
+		}
+	}
+	if strings.Join(yamlKeys, ",") != strings.Join(jsonKeys, ",") {
+		t.Errorf("YAML keys = %v, JSON keys = %v", yamlKeys, jsonKeys)
+	}
+	for _, want := range []string{"groupBy: user\n", "  - key: bob\n", "    ports: 1\n", "  - key: pg\n"} {
+		if !strings.Contains(y, want) {
+			t.Errorf("YAML groups missing %q:\n%s", want, y)
+		}
+	}
+}
+
+func TestYAMLFormatSingleNotInUse(t *testing.T) {
+	got, err := NewYAMLFormatter().FormatSingle(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "listeners: []\n") {
+		t.Errorf("FormatSingle(nil) = %q, want an empty listener list", got)
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{nil, "null"},
+		{true, "true"},
+		{json.Number("-1.25"), "-1.25"},
+		{"node", "node"},
+		{"/usr/bin/python3", "/usr/bin/python3"},
+		{"", `""`},
+		{"no", `"no"`},
+		{"Null", `"Null"`},
+		{"0.0.0.0", `"0.0.0.0"`},
+		{"-m", `"-m"`},
+		{"a b", `"a b"`},
+		{"key: value", `"key: value"`},
+		{"#comment", `"#comment"`},
+		{"line\nbreak", `"line\nbreak"`},
+		{&yamlMapping{}, "{}"},
+		{[]any{}, "[]"},
+	}
+	for _, tt := range tests {
+		if got := yamlScalar(tt.in); got != tt.want {
+			t.Errorf("yamlScalar(%#v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
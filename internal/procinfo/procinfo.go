@@ -0,0 +1,139 @@
+// Package procinfo reads process details the scanners don't provide: the
+// cgroup of a process and where it was launched from (its parent chain,
+// terminal, working directory, and tmux or screen session). On Linux they
+// come from /proc; elsewhere from ps and lsof, and cgroups are empty. On
+// Linux it also reads the socket tables and process details the Linux
+// scanner is built on.
+package procinfo
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// Root is the procfs mount point
+var Root = "/proc"
+
+// maxDepth bounds parent chains, guarding against PID reuse loops
+const maxDepth = 64
+
+// Context is where a process was launched from
+type Context struct {
+	PPID    int
+	Parents []model.ProcessRef // Nearest first, up to init
+	TTY     string
+	Cwd     string
+	Session *model.TerminalSession
+}
+
+// proc is one entry of the process table
+type proc struct {
+	PID     int
+	PPID    int
+	Name    string
+	Cmdline []string
+	TTY     string
+}
+
+// buildContext assembles the context of a process from the process table,
+// its working directory, and its environment (nil when unreadable)
+func buildContext(pid int, lookup func(pid int) (proc, bool), cwd string, environ []string) Context {
+	c := Context{Cwd: cwd}
+
+	p, ok := lookup(pid)
+	if !ok {
+		return c
+	}
+	c.PPID = p.PPID
+	c.TTY = p.TTY
+
+	seen := map[int]bool{pid: true}
+	for next := p.PPID; next > 0 && !seen[next] && len(c.Parents) < maxDepth; {
+		seen[next] = true
+		parent, ok := lookup(next)
+		if !ok {
+			break
+		}
+		c.Parents = append(c.Parents, model.ProcessRef{PID: parent.PID, Name: parent.Name, Cmdline: parent.Cmdline})
+		next = parent.PPID
+	}
+
+	c.Session = sessionFromEnv(environ)
+	if c.Session == nil {
+		c.Session = sessionFromParents(c.Parents)
+	}
+	return c
+}
+
+// sessionFromEnv detects tmux from $TMUX ("/tmp/tmux-1000/default,1234,0")
+// and $TMUX_PANE, and screen from $STY ("12345.pts-0.host")
+func sessionFromEnv(environ []string) *model.TerminalSession {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	if tmux := env["TMUX"]; tmux != "" {
+		socket, _, _ := strings.Cut(tmux, ",")
+		return &model.TerminalSession{Multiplexer: "tmux", Name: filepath.Base(socket), Pane: env["TMUX_PANE"]}
+	}
+	if sty := env["STY"]; sty != "" {
+		return &model.TerminalSession{Multiplexer: "screen", Name: sty}
+	}
+	return nil
+}
+
+// sessionFromParents detects a tmux or screen server among the ancestors,
+// for when the environment can't be read
+func sessionFromParents(parents []model.ProcessRef) *model.TerminalSession {
+	for _, p := range parents {
+		name := strings.ToLower(p.Name)
+		switch {
+		case strings.HasPrefix(name, "tmux"):
+			return &model.TerminalSession{Multiplexer: "tmux"}
+		case name == "screen":
+			return &model.TerminalSession{Multiplexer: "screen"}
+		}
+	}
+	return nil
+}
+
+// parseCgroup picks the cgroup path of a process from the contents of
+// /proc/<pid>/cgroup: the unified (v2) hierarchy if mounted, else the
+// memory or cpu controller of v1, else the first hierarchy listed. On
+// hybrid systems the unified path is "/" for processes that only v1
+// places, such as containers run with the cgroupfs driver.
+func parseCgroup(data string) string {
+	var first, v1, unified string
+	for _, line := range strings.Split(data, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		id, controllers, path := parts[0], parts[1], parts[2]
+
+		if id == "0" && controllers == "" {
+			unified = path
+			continue
+		}
+		if first == "" {
+			first = path
+		}
+		for _, c := range strings.Split(controllers, ",") {
+			if v1 == "" && (c == "memory" || c == "cpu") {
+				v1 = path
+			}
+		}
+	}
+	if unified != "" && (unified != "/" || v1 == "") {
+		return unified
+	}
+	if v1 != "" {
+		return v1
+	}
+	return first
+}
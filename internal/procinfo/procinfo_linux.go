@@ -0,0 +1,355 @@
+//go:build linux
+
+package procinfo
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// Cgroup returns the cgroup path of a process, or "" when unreadable
+func Cgroup(pid int) string {
+	data, err := os.ReadFile(filepath.Join(Root, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	return parseCgroup(string(data))
+}
+
+// Contexts returns the launch context of each process, reading
+// /proc/<pid>/stat, cmdline, cwd and environ. Fields that can't be read,
+// e.g. another user's cwd without privileges, are left empty.
+func Contexts(pids []int) map[int]Context {
+	table := make(map[int]proc)
+	lookup := func(pid int) (proc, bool) {
+		if p, ok := table[pid]; ok {
+			return p, p.PID != 0
+		}
+		p, err := readProc(pid)
+		if err != nil {
+			p = proc{}
+		}
+		table[pid] = p
+		return p, p.PID != 0
+	}
+
+	contexts := make(map[int]Context, len(pids))
+	for _, pid := range pids {
+		if _, ok := contexts[pid]; ok {
+			continue
+		}
+		dir := filepath.Join(Root, strconv.Itoa(pid))
+		cwd, _ := os.Readlink(filepath.Join(dir, "cwd"))
+		var environ []string
+		if data, err := os.ReadFile(filepath.Join(dir, "environ")); err == nil {
+			environ = splitNUL(data)
+		}
+		contexts[pid] = buildContext(pid, lookup, cwd, environ)
+	}
+	return contexts
+}
+
+// readProc reads a process table entry from /proc/<pid>
+func readProc(pid int) (proc, error) {
+	name, fields, err := readStat(pid)
+	if err != nil {
+		return proc{}, err
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	ttyNr, _ := strconv.Atoi(fields[4])
+
+	p := proc{PID: pid, PPID: ppid, Name: name, TTY: ttyName(ttyNr)}
+	if cmdline, err := os.ReadFile(filepath.Join(Root, strconv.Itoa(pid), "cmdline")); err == nil {
+		p.Cmdline = splitNUL(cmdline)
+	}
+	return p, nil
+}
+
+// readStat reads /proc/<pid>/stat, returning the process name and the
+// fields after it, starting with the state (field 3 in proc(5))
+func readStat(pid int) (string, []string, error) {
+	dir := filepath.Join(Root, strconv.Itoa(pid))
+	data, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The name is in parentheses and may itself contain spaces or ")"
+	stat := string(data)
+	open, end := strings.IndexByte(stat, '('), strings.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return "", nil, fmt.Errorf("malformed %s/stat", dir)
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 20 {
+		return "", nil, fmt.Errorf("malformed %s/stat", dir)
+	}
+	return stat[open+1 : end], fields, nil
+}
+
+// clockTicks is USER_HZ, the unit of the times in /proc/<pid>/stat, which
+// is 100 on every architecture Linux exposes to userspace
+const clockTicks = 100
+
+// Process reads the name, command line, owner and start time of a process
+// from /proc/<pid>
+func Process(pid int) (*model.Process, error) {
+	name, fields, err := readStat(pid)
+	if err != nil {
+		return nil, err
+	}
+	p := &model.Process{PID: pid, Name: name, Command: name}
+	if cmdline, err := os.ReadFile(filepath.Join(Root, strconv.Itoa(pid), "cmdline")); err == nil {
+		p.Cmdline = splitNUL(cmdline)
+	}
+	if uid, ok := processUID(pid); ok {
+		p.UID = uid
+		p.User = userName(uid)
+	}
+	if ticks, err := strconv.ParseInt(fields[19], 10, 64); err == nil {
+		if boot := bootTime(); !boot.IsZero() {
+			p.StartTime = boot.Add(time.Duration(ticks) * time.Second / clockTicks)
+			p.UptimeSeconds = int64(time.Since(p.StartTime).Seconds())
+		}
+	}
+	return p, nil
+}
+
+// Stats reads the memory, CPU, open file and thread counts of a process.
+// CPU is averaged over the process's lifetime, as ps reports it.
+func Stats(pid int) *model.ProcessStats {
+	stats := &model.ProcessStats{}
+	dir := filepath.Join(Root, strconv.Itoa(pid))
+
+	_, fields, err := readStat(pid)
+	if err == nil {
+		stats.ThreadCount, _ = strconv.Atoi(fields[17])
+		utime, _ := strconv.ParseInt(fields[11], 10, 64)
+		stime, _ := strconv.ParseInt(fields[12], 10, 64)
+		start, _ := strconv.ParseInt(fields[19], 10, 64)
+		if boot := bootTime(); !boot.IsZero() {
+			elapsed := time.Since(boot).Seconds() - float64(start)/clockTicks
+			if elapsed > 0 {
+				stats.CPUPercent = float64(utime+stime) / clockTicks / elapsed * 100
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "statm")); err == nil {
+		if f := strings.Fields(string(data)); len(f) >= 2 {
+			pages, _ := strconv.ParseInt(f[1], 10, 64)
+			stats.MemoryRSS = pages * int64(os.Getpagesize())
+		}
+	}
+	if fds, err := os.ReadDir(filepath.Join(dir, "fd")); err == nil {
+		stats.FDCount = len(fds)
+	}
+	return stats
+}
+
+// processUID reads the real UID of a process from /proc/<pid>/status
+func processUID(pid int) (int, bool) {
+	data, err := os.ReadFile(filepath.Join(Root, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(line, "Uid:"); ok {
+			if f := strings.Fields(rest); len(f) > 0 {
+				uid, err := strconv.Atoi(f[0])
+				return uid, err == nil
+			}
+		}
+	}
+	return 0, false
+}
+
+var (
+	usersMu sync.Mutex
+	users   = make(map[int]string)
+)
+
+// userName returns the name of a UID, or the UID itself when it has none
+func userName(uid int) string {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	if name, ok := users[uid]; ok {
+		return name
+	}
+	name := strconv.Itoa(uid)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	users[uid] = name
+	return name
+}
+
+var (
+	bootOnce sync.Once
+	boot     time.Time
+)
+
+// bootTime reads the boot time from the btime line of /proc/stat, or
+// returns the zero time when it can't
+func bootTime() time.Time {
+	bootOnce.Do(func() {
+		data, err := os.ReadFile(filepath.Join(Root, "stat"))
+		if err != nil {
+			return
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if rest, ok := strings.CutPrefix(line, "btime "); ok {
+				if sec, err := strconv.ParseInt(strings.TrimSpace(rest), 10, 64); err == nil {
+					boot = time.Unix(sec, 0)
+				}
+				return
+			}
+		}
+	})
+	return boot
+}
+
+// ttyName names the controlling terminal encoded in the tty_nr field of
+// /proc/<pid>/stat, or returns "" for none
+func ttyName(nr int) string {
+	if nr == 0 {
+		return ""
+	}
+	major := (nr >> 8) & 0xfff
+	minor := (nr & 0xff) | ((nr >> 12) & 0xfff00)
+	switch {
+	case major >= 136 && major <= 143: // Unix98 pseudo-terminals
+		return fmt.Sprintf("pts/%d", (major-136)*256+minor)
+	case major == 4 && minor < 64:
+		return fmt.Sprintf("tty%d", minor)
+	case major == 4:
+		return fmt.Sprintf("ttyS%d", minor-64)
+	}
+	return fmt.Sprintf("%d:%d", major, minor)
+}
+
+// splitNUL splits NUL-separated /proc data such as cmdline and environ
+func splitNUL(data []byte) []string {
+	s := strings.TrimRight(string(data), "\x00")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\x00")
+}
+
+// ListeningProcess finds the process listening on a port in the network
+// namespace of process nsPID, such as a container's main process. It
+// returns nil when there is none or /proc can't be read, which usually
+// takes root for other users' processes.
+func ListeningProcess(nsPID, port int, proto string) *model.ProcessRef {
+	nsDir := filepath.Join(Root, strconv.Itoa(nsPID))
+	inode := listeningInode(nsDir, port, proto)
+	if inode == "" {
+		return nil
+	}
+	netns, err := os.Readlink(filepath.Join(nsDir, "ns", "net"))
+	if err != nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir(Root)
+	if err != nil {
+		return nil
+	}
+	socket := "socket:[" + inode + "]"
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join(Root, e.Name())
+		if ns, err := os.Readlink(filepath.Join(dir, "ns", "net")); err != nil || ns != netns {
+			continue
+		}
+		fds, err := os.ReadDir(filepath.Join(dir, "fd"))
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if link, err := os.Readlink(filepath.Join(dir, "fd", fd.Name())); err == nil && link == socket {
+				p, err := readProc(pid)
+				if err != nil {
+					return nil
+				}
+				return &model.ProcessRef{PID: p.PID, Name: p.Name, Cmdline: p.Cmdline}
+			}
+		}
+	}
+	return nil
+}
+
+// listeningInode returns the socket inode listening on a port, from the
+// /proc/<pid>/net tables of the process's network namespace
+func listeningInode(dir string, port int, proto string) string {
+	for _, table := range []string{proto, proto + "6"} {
+		sockets, err := ReadSockets(dir, table)
+		if err != nil {
+			continue
+		}
+		for _, s := range sockets {
+			if s.Listening() && s.LocalPort == port {
+				return s.Inode
+			}
+		}
+	}
+	return ""
+}
+
+// ReadSockets reads a socket table, e.g. "tcp6", from dir/net, where dir is
+// Root for the current network namespace or Root/<pid> for a process's
+func ReadSockets(dir, table string) ([]Socket, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "net", table))
+	if err != nil {
+		return nil, err
+	}
+	return parseSockets(string(data), strings.TrimSuffix(table, "6")), nil
+}
+
+// SocketOwners maps socket inodes to the PID of a process holding them
+// open, by reading the /proc/<pid>/fd links. Sockets of other users'
+// processes are missing unless running as root.
+func SocketOwners(inodes map[string]bool) map[string]int {
+	owners := make(map[string]int)
+	entries, err := os.ReadDir(Root)
+	if err != nil {
+		return owners
+	}
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join(Root, e.Name(), "fd")
+		fds, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(dir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			inode, ok := strings.CutPrefix(link, "socket:[")
+			if !ok {
+				continue
+			}
+			inode = strings.TrimSuffix(inode, "]")
+			if _, seen := owners[inode]; !seen && inodes[inode] {
+				owners[inode] = pid
+			}
+		}
+	}
+	return owners
+}
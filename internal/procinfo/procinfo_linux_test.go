@@ -0,0 +1,98 @@
+//go:build linux
+
+package procinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeNet writes socket tables into dir/net, as in /proc/<pid>/net
+func writeNet(t *testing.T, dir string, tables map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "net"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range tables {
+		if err := os.WriteFile(filepath.Join(dir, "net", name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const header = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+func TestListeningInode(t *testing.T) {
+	dir := t.TempDir()
+	writeNet(t, dir, map[string]string{
+		// Port 80 established to a client, then listening; 8080 listening
+		"tcp": header +
+			"   0: 020011AC:0050 010011AC:D431 01 00000000:00000000 00:00000000 00000000     0        0 1111 1\n" +
+			"   1: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 2222 1\n",
+		// Port 5432 listening on IPv6 only
+		"tcp6": header +
+			"   0: 00000000000000000000000000000000:1538 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 3333 1\n",
+		// An unconnected UDP socket on 53, and a connected one on 5353
+		"udp": header +
+			"   0: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 4444 2\n" +
+			"   1: 020011AC:14E9 010011AC:0035 01 00000000:00000000 00:00000000 00000000     0        0 5555 2\n",
+	})
+
+	tests := []struct {
+		port  int
+		proto string
+		want  string
+	}{
+		{80, "tcp", "2222"},
+		{5432, "tcp", "3333"},
+		{53, "udp", "4444"},
+		{5353, "udp", ""},
+		{53, "tcp", ""},
+		{9999, "tcp", ""},
+	}
+	for _, tt := range tests {
+		if got := listeningInode(dir, tt.port, tt.proto); got != tt.want {
+			t.Errorf("listeningInode(%d/%s) = %q, want %q", tt.port, tt.proto, got, tt.want)
+		}
+	}
+
+	if got := listeningInode(t.TempDir(), 80, "tcp"); got != "" {
+		t.Errorf("listeningInode() without tables = %q", got)
+	}
+}
+
+func TestReadSockets(t *testing.T) {
+	dir := t.TempDir()
+	writeNet(t, dir, map[string]string{
+		"udp6": header + "   0: 00000000000000000000000001000000:0035 00000000000000000000000000000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 77 2\n",
+	})
+
+	sockets, err := ReadSockets(dir, "udp6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Socket{Protocol: "udp", LocalAddr: "::1", LocalPort: 53, RemoteAddr: "::", State: StateUnconnected, UID: 101, Inode: "77"}
+	if len(sockets) != 1 || sockets[0] != want {
+		t.Errorf("ReadSockets() = %+v, want [%+v]", sockets, want)
+	}
+	if _, err := ReadSockets(dir, "tcp"); err == nil {
+		t.Error("ReadSockets() of a missing table succeeded")
+	}
+}
+
+func TestProcessSelf(t *testing.T) {
+	p, err := Process(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.PID != os.Getpid() || p.Name == "" || len(p.Cmdline) == 0 || p.UID != os.Getuid() {
+		t.Errorf("Process(self) = %+v", p)
+	}
+	if p.StartTime.IsZero() || p.UptimeSeconds < 0 {
+		t.Errorf("Process(self) start = %v, uptime %d", p.StartTime, p.UptimeSeconds)
+	}
+	if s := Stats(os.Getpid()); s.MemoryRSS <= 0 || s.ThreadCount <= 0 || s.FDCount <= 0 {
+		t.Errorf("Stats(self) = %+v", s)
+	}
+}
@@ -0,0 +1,121 @@
+//go:build !linux
+
+package procinfo
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// Cgroup returns "" as cgroups only exist on Linux
+func Cgroup(pid int) string {
+	return ""
+}
+
+// ListeningProcess returns nil, as network namespaces only exist on Linux
+func ListeningProcess(nsPID, port int, proto string) *model.ProcessRef {
+	return nil
+}
+
+// Contexts returns the launch context of each process from one ps listing
+// of the process table and one lsof call for working directories. Other
+// processes' environments aren't readable here, so sessions are detected
+// from the parent chain only.
+func Contexts(pids []int) map[int]Context {
+	table := processTable()
+	lookup := func(pid int) (proc, bool) {
+		p, ok := table[pid]
+		return p, ok
+	}
+	cwds := workingDirs(pids)
+
+	contexts := make(map[int]Context, len(pids))
+	for _, pid := range pids {
+		contexts[pid] = buildContext(pid, lookup, cwds[pid], nil)
+	}
+	return contexts
+}
+
+// processTable lists every process using `ps -axww`. Arguments are split on
+// whitespace, as ps reports them joined.
+func processTable() map[int]proc {
+	table := make(map[int]proc)
+
+	output, err := exec.Command("ps", "-axww", "-o", "pid=,ppid=,tty=,comm=").Output()
+	if err != nil {
+		return table
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		tty := fields[2]
+		if tty == "??" || tty == "-" {
+			tty = ""
+		}
+		name := filepath.Base(strings.Join(fields[3:], " "))
+		table[pid] = proc{PID: pid, PPID: ppid, Name: name, TTY: tty}
+	}
+
+	output, err = exec.Command("ps", "-axww", "-o", "pid=,args=").Output()
+	if err != nil {
+		return table
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		if p, ok := table[pid]; ok {
+			p.Cmdline = fields[1:]
+			table[pid] = p
+		}
+	}
+	return table
+}
+
+// workingDirs returns the working directory of each PID using
+// `lsof -a -d cwd -Fpn`
+func workingDirs(pids []int) map[int]string {
+	cwds := make(map[int]string)
+	if len(pids) == 0 {
+		return cwds
+	}
+
+	list := make([]string, len(pids))
+	for i, pid := range pids {
+		list[i] = strconv.Itoa(pid)
+	}
+	// lsof exits non-zero when it can't read some of the processes
+	output, _ := exec.Command("lsof", "-a", "-d", "cwd", "-Fpn", "-p", strings.Join(list, ",")).Output()
+
+	pid := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			pid, _ = strconv.Atoi(line[1:])
+		case 'n':
+			if pid > 0 {
+				cwds[pid] = line[1:]
+			}
+		}
+	}
+	return cwds
+}
@@ -0,0 +1,46 @@
+package procinfo
+
+import "testing"
+
+func TestParseCgroup(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			"v2",
+			"0::/system.slice/docker-4f6c.scope\n",
+			"/system.slice/docker-4f6c.scope",
+		},
+		{
+			"v1",
+			"12:pids:/docker/4f6c\n11:memory:/docker/4f6c\n4:cpu,cpuacct:/docker/4f6c\n1:name=systemd:/docker/4f6c\n",
+			"/docker/4f6c",
+		},
+		{
+			"v1 without memory or cpu",
+			"2:pids:/docker/4f6c\n1:name=systemd:/system.slice/docker.service\n",
+			"/docker/4f6c",
+		},
+		{
+			"hybrid, placed by v2",
+			"11:memory:/user.slice\n1:name=systemd:/user.slice/session-2.scope\n0::/user.slice/session-2.scope\n",
+			"/user.slice/session-2.scope",
+		},
+		{
+			"hybrid, placed by v1 only",
+			"11:memory:/docker/4f6c\n1:name=systemd:/docker/4f6c\n0::/\n",
+			"/docker/4f6c",
+		},
+		{"v2 root", "0::/\n", "/"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseCgroup(tt.data); got != tt.want {
+				t.Errorf("parseCgroup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package procinfo
+
+import (
+	"encoding/hex"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Socket states as written in the st column of /proc/net/tcp and udp
+const (
+	StateEstablished = "01"
+	StateUnconnected = "07" // An unconnected UDP socket, i.e. one listening
+	StateListen      = "0A"
+)
+
+// Socket is an entry of a /proc/net socket table such as /proc/net/tcp
+type Socket struct {
+	Protocol   string // "tcp" or "udp"
+	LocalAddr  string
+	LocalPort  int
+	RemoteAddr string
+	RemotePort int
+	State      string // Hex state, e.g. StateListen
+	UID        int
+	Inode      string
+}
+
+// Listening reports whether the socket accepts connections or datagrams
+func (s Socket) Listening() bool {
+	if s.Protocol == "udp" {
+		return s.State == StateUnconnected
+	}
+	return s.State == StateListen
+}
+
+// parseSockets parses a socket table, skipping its header and malformed
+// lines:
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+//	 0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 41712 ...
+func parseSockets(data, proto string) []Socket {
+	var sockets []Socket
+	for _, line := range strings.Split(data, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+		localAddr, localPort, ok := parseHexAddr(fields[1])
+		if !ok {
+			continue
+		}
+		remoteAddr, remotePort, ok := parseHexAddr(fields[2])
+		if !ok {
+			continue
+		}
+		uid, _ := strconv.Atoi(fields[7])
+		sockets = append(sockets, Socket{
+			Protocol:   proto,
+			LocalAddr:  localAddr,
+			LocalPort:  localPort,
+			RemoteAddr: remoteAddr,
+			RemotePort: remotePort,
+			State:      fields[3],
+			UID:        uid,
+			Inode:      fields[9],
+		})
+	}
+	return sockets
+}
+
+// parseHexAddr decodes an address:port pair of a socket table. The address
+// is written as 32-bit words in host byte order, little-endian here, and
+// the port in hex, e.g. "0100007F:1F90" is 127.0.0.1:8080.
+func parseHexAddr(s string) (string, int, bool) {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, false
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, false
+	}
+	b, err := hex.DecodeString(hexIP)
+	if err != nil || (len(b) != net.IPv4len && len(b) != net.IPv6len) {
+		return "", 0, false
+	}
+	for i := 0; i < len(b); i += 4 {
+		b[i], b[i+1], b[i+2], b[i+3] = b[i+3], b[i+2], b[i+1], b[i]
+	}
+	// IPv4-mapped addresses of dual-stack sockets print as IPv4
+	return net.IP(b).String(), int(port), true
+}
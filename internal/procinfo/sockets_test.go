@@ -0,0 +1,56 @@
+package procinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHexAddr(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantAddr string
+		wantPort int
+		wantOK   bool
+	}{
+		{"0100007F:1F90", "127.0.0.1", 8080, true},
+		{"00000000:0016", "0.0.0.0", 22, true},
+		{"00000000000000000000000000000000:0050", "::", 80, true},
+		{"00000000000000000000000001000000:1538", "::1", 5432, true},
+		{"0000000000000000FFFF00000100007F:0277", "127.0.0.1", 631, true}, // IPv4-mapped
+		{"000080FE000000000000000001000000:0035", "fe80::1", 53, true},
+		{"0100007F", "", 0, false},
+		{"0100007:1F90", "", 0, false},
+		{"0100007F:XYZ", "", 0, false},
+	}
+	for _, tt := range tests {
+		addr, port, ok := parseHexAddr(tt.in)
+		if addr != tt.wantAddr || port != tt.wantPort || ok != tt.wantOK {
+			t.Errorf("parseHexAddr(%q) = %q, %d, %v; want %q, %d, %v", tt.in, addr, port, ok, tt.wantAddr, tt.wantPort, tt.wantOK)
+		}
+	}
+}
+
+const tcpTable = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 41712 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:D431 01 00000000:00000000 00:00000000 00000000  1000        0 41800 1 0000000000000000 20 4 30 10 -1
+   2: malformed
+`
+
+func TestParseSockets(t *testing.T) {
+	got := parseSockets(tcpTable, "tcp")
+	want := []Socket{
+		{Protocol: "tcp", LocalAddr: "127.0.0.1", LocalPort: 8080, RemoteAddr: "0.0.0.0", State: StateListen, UID: 1000, Inode: "41712"},
+		{Protocol: "tcp", LocalAddr: "127.0.0.1", LocalPort: 8080, RemoteAddr: "127.0.0.1", RemotePort: 54321, State: StateEstablished, UID: 1000, Inode: "41800"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSockets() = %+v, want %+v", got, want)
+	}
+	if !got[0].Listening() || got[1].Listening() {
+		t.Error("Listening() should only hold for the LISTEN socket")
+	}
+
+	udp := Socket{Protocol: "udp", State: StateUnconnected}
+	if !udp.Listening() {
+		t.Error("an unconnected UDP socket should be listening")
+	}
+}
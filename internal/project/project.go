@@ -0,0 +1,77 @@
+// Package project maps directories to the project they belong to, so
+// listeners can be told apart by the checkout their server runs from.
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// Markers are the files and directories that mark a project root, in the
+// order they are checked within one directory
+var Markers = []string{".git", "package.json", "go.mod", "pyproject.toml"}
+
+// Detect walks up from dir to the nearest directory holding a marker and
+// returns that project, or nil when there is none
+func Detect(dir string) *model.Project {
+	if dir == "" {
+		return nil
+	}
+
+	var p *model.Project
+	for d := filepath.Clean(dir); ; {
+		for _, marker := range Markers {
+			if _, err := os.Stat(filepath.Join(d, marker)); err != nil {
+				continue
+			}
+			if p == nil {
+				p = &model.Project{Name: filepath.Base(d), Root: d, Marker: marker}
+			}
+			if marker == ".git" {
+				p.Repo = d
+				return p
+			}
+		}
+
+		parent := filepath.Dir(d)
+		if parent == d {
+			return p
+		}
+		d = parent
+	}
+}
+
+// Resolve returns the project of a directory given on the command line,
+// which may be relative or reached through symlinks
+func Resolve(dir string) *model.Project {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = real
+	}
+	return Detect(abs)
+}
+
+// Contains reports whether project q belongs to p: it is p itself, or a
+// package inside p's repository, such as a workspace of a monorepo. Other
+// worktrees and nested repositories are separate projects.
+func Contains(p, q *model.Project) bool {
+	if p == nil || q == nil {
+		return false
+	}
+	if q.Root == p.Root {
+		return true
+	}
+	return p.Repo != "" && q.Repo == p.Repo && within(q.Root, p.Root)
+}
+
+// within reports whether path is dir or below it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -20,6 +20,10 @@ func NewDarwinScanner(opts Options) *DarwinScanner {
 	return &DarwinScanner{opts: opts}
 }
 
+func newPlatformScanner(opts Options) (Scanner, error) {
+	return NewDarwinScanner(opts), nil
+}
+
 func (s *DarwinScanner) ListListeners() ([]model.Listener, error) {
 	args := []string{"-i", "-n", "-P"}
 	if s.opts.IncludeTCP && !s.opts.IncludeUDP {
@@ -32,7 +36,71 @@ func (s *DarwinScanner) ListListeners() ([]model.Listener, error) {
 		return nil, fmt.Errorf("lsof failed: %w", err)
 	}
 
-	return s.parseLsofOutput(string(output))
+	listeners, err := s.parseLsofOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	s.addProcessDetails(listeners)
+	return listeners, nil
+}
+
+// addProcessDetails fills in command lines for all listeners with a single
+// ps call, and process stats when FetchStats is set.
+func (s *DarwinScanner) addProcessDetails(listeners []model.Listener) {
+	var pids []string
+	seen := make(map[int]bool)
+	for _, l := range listeners {
+		if l.PID > 0 && !seen[l.PID] {
+			seen[l.PID] = true
+			pids = append(pids, strconv.Itoa(l.PID))
+		}
+	}
+	if len(pids) == 0 {
+		return
+	}
+
+	cmdlines := getCmdlines(pids)
+	stats := make(map[int]*model.ProcessStats)
+
+	for i := range listeners {
+		l := &listeners[i]
+		if l.Process != nil {
+			l.Process.Cmdline = cmdlines[l.PID]
+		}
+		if s.opts.FetchStats {
+			if _, ok := stats[l.PID]; !ok {
+				stats[l.PID] = s.getProcessStats(l.PID)
+			}
+			l.Stats = stats[l.PID]
+		}
+	}
+}
+
+// getCmdlines returns the command line of each PID using `ps -o pid=,args=`.
+// Arguments are split on whitespace, as ps reports them joined.
+func getCmdlines(pids []string) map[int][]string {
+	cmdlines := make(map[int][]string)
+
+	cmd := exec.Command("ps", "-o", "pid=,args=", "-p", strings.Join(pids, ","))
+	output, err := cmd.Output()
+	if err != nil {
+		return cmdlines
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		cmdlines[pid] = fields[1:]
+	}
+
+	return cmdlines
 }
 
 func (s *DarwinScanner) GetPort(port int) (*model.Listener, error) {
@@ -56,6 +124,9 @@ func (s *DarwinScanner) GetPort(port int) (*model.Listener, error) {
 	listener.Connections = connections
 	listener.ConnectionCount = len(connections)
 	listener.Stats = s.getProcessStats(listener.PID)
+	if listener.Process != nil {
+		listener.Process.Cmdline = getCmdlines([]string{strconv.Itoa(listener.PID)})[listener.PID]
+	}
 
 	return listener, nil
 }
@@ -174,42 +245,7 @@ func (s *DarwinScanner) FindByPattern(pattern string) ([]model.Listener, error)
 	if err != nil {
 		return nil, err
 	}
-
-	var matches []model.Listener
-	patternLower := strings.ToLower(pattern)
-
-	// Check if pattern is a port number
-	patternPort, isPort := strconv.Atoi(pattern)
-
-	for _, l := range listeners {
-		// Match by port number
-		if isPort == nil && l.Port == patternPort {
-			matches = append(matches, l)
-			continue
-		}
-
-		// Match by PID
-		if isPort == nil && l.PID == patternPort {
-			matches = append(matches, l)
-			continue
-		}
-
-		if l.Process == nil {
-			continue
-		}
-
-		name := strings.ToLower(l.Process.Name)
-		cmd := strings.ToLower(l.Process.Command)
-		user := strings.ToLower(l.Process.User)
-
-		if strings.Contains(name, patternLower) ||
-			strings.Contains(cmd, patternLower) ||
-			strings.Contains(user, patternLower) {
-			matches = append(matches, l)
-		}
-	}
-
-	return matches, nil
+	return matchPattern(listeners, pattern), nil
 }
 
 // lsofEntry represents a parsed line from lsof output.
@@ -0,0 +1,129 @@
+package scanner
+
+import (
+	"github.com/tasnimzotder/portman/internal/container"
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/procinfo"
+	"github.com/tasnimzotder/portman/internal/project"
+	"github.com/tasnimzotder/portman/internal/services"
+)
+
+// enrichScanner adds what platform scanners don't collect to every
+// listener they return: the service name, process details read by
+// procinfo, the container the process runs in, and where docker-proxy
+// forwards to
+type enrichScanner struct {
+	Scanner
+	opts   Options
+	docker *dockerAPI
+}
+
+func (s enrichScanner) ListListeners() ([]model.Listener, error) {
+	s.docker.refresh()
+	listeners, err := s.Scanner.ListListeners()
+	s.enrich(listeners)
+	return listeners, err
+}
+
+func (s enrichScanner) GetPort(port int) (*model.Listener, error) {
+	s.docker.refresh()
+	l, err := s.Scanner.GetPort(port)
+	if l != nil {
+		listeners := []model.Listener{*l}
+		s.enrich(listeners)
+		*l = listeners[0]
+	}
+	if l == nil && err == nil {
+		l = s.publishedPort(port)
+	}
+	return l, err
+}
+
+func (s enrichScanner) FindByPattern(pattern string) ([]model.Listener, error) {
+	s.docker.refresh()
+	listeners, err := s.Scanner.FindByPattern(pattern)
+	s.enrich(listeners)
+	return listeners, err
+}
+
+// enrich sets the service of each listener and fills in process details,
+// reading each process once
+func (s enrichScanner) enrich(listeners []model.Listener) {
+	services.Annotate(listeners)
+	docker := s.docker
+
+	var pids []int
+	cgroups := make(map[int]string)
+	containers := make(map[int]*model.Container)
+	for i := range listeners {
+		p := listeners[i].Process
+		if p == nil || p.PID <= 0 {
+			continue
+		}
+		cgroup, ok := cgroups[p.PID]
+		if !ok {
+			cgroup = procinfo.Cgroup(p.PID)
+			cgroups[p.PID] = cgroup
+			containers[p.PID] = container.Parse(cgroup)
+			pids = append(pids, p.PID)
+		}
+		p.Cgroup = cgroup
+		p.Container = containers[p.PID]
+	}
+	nameContainers(containers, docker)
+	resolveForwards(listeners, docker)
+
+	if !s.opts.FetchContext || len(pids) == 0 {
+		return
+	}
+	contexts := procinfo.Contexts(pids)
+	projects := make(map[string]*model.Project) // By working directory
+	for i := range listeners {
+		p := listeners[i].Process
+		if p == nil {
+			continue
+		}
+		if c, ok := contexts[p.PID]; ok {
+			p.PPID = c.PPID
+			p.Parents = c.Parents
+			p.TTY = c.TTY
+			p.Cwd = c.Cwd
+			p.Session = c.Session
+
+			proj, ok := projects[c.Cwd]
+			if !ok {
+				proj = project.Detect(c.Cwd)
+				projects[c.Cwd] = proj
+			}
+			p.Project = proj
+		}
+	}
+}
+
+// nameContainers sets the name and image of containers from the Docker
+// Engine API, when a socket is configured. Containers stay unnamed if the
+// API can't be reached.
+func nameContainers(containers map[int]*model.Container, docker *dockerAPI) {
+	var found bool
+	for _, c := range containers {
+		found = found || c != nil
+	}
+	if !found {
+		return
+	}
+
+	infos := docker.containers()
+	byID := make(map[string]container.Info, len(infos))
+	for _, info := range infos {
+		byID[info.ID] = info
+	}
+	for _, c := range containers {
+		if c == nil {
+			continue
+		}
+		if info, ok := byID[c.ID]; ok {
+			c.Name = info.Name
+			c.Image = info.Image
+		}
+	}
+}
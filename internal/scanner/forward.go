@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"github.com/tasnimzotder/portman/internal/container"
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/procinfo"
+	"github.com/tasnimzotder/portman/internal/services"
+)
+
+// dockerAPI lists the running containers at most once per scan, and only
+// when something needs them. A scanner keeps one for its lifetime, so
+// every scan reuses the same API connection. A nil dockerAPI, used without
+// a socket, knows no containers.
+type dockerAPI struct {
+	client *container.Client
+	infos  []container.Info
+	listed bool
+}
+
+// newDockerAPI returns the Docker Engine API at socket, or nil for ""
+func newDockerAPI(socket string) *dockerAPI {
+	if socket == "" {
+		return nil
+	}
+	return &dockerAPI{client: container.NewClient(socket)}
+}
+
+// refresh forgets the containers listed by the last scan
+func (d *dockerAPI) refresh() {
+	if d == nil {
+		return
+	}
+	d.infos, d.listed = nil, false
+}
+
+// containers returns the running containers, or none when the API can't
+// be reached
+func (d *dockerAPI) containers() []container.Info {
+	if d == nil {
+		return nil
+	}
+	if !d.listed {
+		d.listed = true
+		d.infos, _ = d.client.Containers()
+	}
+	return d.infos
+}
+
+// resolveForwards follows each docker-proxy listener to the container
+// port it forwards to. The address comes from the proxy's arguments; with
+// the Docker Engine API, the container and the process listening in it
+// are found too. Other host processes holding a port that the API says is
+// published, like Docker Desktop's com.docker.backend, forward it too.
+func resolveForwards(listeners []model.Listener, docker *dockerAPI) {
+	for i := range listeners {
+		l := &listeners[i]
+		if l.Process == nil {
+			continue
+		}
+		if container.IsProxy(l.Process) {
+			f := container.ProxyForward(l.Process.Cmdline)
+			if f == nil {
+				continue
+			}
+			if info, ok := container.Match(docker.containers(), f, l.Port, l.Protocol); ok {
+				docker.resolve(f, info, l.Protocol)
+			}
+			l.Forward = f
+			continue
+		}
+
+		// A process inside a container holds its own port
+		if l.Process.Container != nil || docker == nil {
+			continue
+		}
+		info, mapping, ok := container.PublishedAt(docker.containers(), l.Address, l.Port, l.Protocol)
+		if !ok {
+			continue
+		}
+		f := &model.Forward{Via: container.ForwarderName(l.Process), ContainerPort: mapping.ContainerPort}
+		docker.resolve(f, info, l.Protocol)
+		l.Forward = f
+	}
+}
+
+// publishedPort returns a listener for a port that a container publishes
+// without a docker-proxy process, where the kernel forwards it (e.g. an
+// iptables DNAT rule), or nil
+func (s enrichScanner) publishedPort(port int) *model.Listener {
+	docker := s.docker
+	infos := docker.containers()
+	for _, proto := range []string{"tcp", "udp"} {
+		if (proto == "tcp" && !s.opts.IncludeTCP) || (proto == "udp" && !s.opts.IncludeUDP) {
+			continue
+		}
+		info, mapping, ok := container.Published(infos, port, proto)
+		if !ok {
+			continue
+		}
+
+		address := mapping.HostIP
+		if address == "" {
+			address = "0.0.0.0"
+		}
+		f := &model.Forward{Via: "docker", ContainerPort: mapping.ContainerPort}
+		if len(info.IPs) > 0 {
+			f.ContainerIP = info.IPs[0]
+		}
+		docker.resolve(f, info, proto)
+
+		listeners := []model.Listener{{Port: port, Protocol: proto, Address: address, Forward: f}}
+		services.Annotate(listeners)
+		return &listeners[0]
+	}
+	return nil
+}
+
+// resolve sets the container of a forward, and the process listening on
+// the container port, found in the container's network namespace
+func (d *dockerAPI) resolve(f *model.Forward, info container.Info, proto string) {
+	f.Container = &model.Container{ID: info.ID, Runtime: "docker", Name: info.Name, Image: info.Image}
+	if f.ContainerIP == "" && len(info.IPs) > 0 {
+		f.ContainerIP = info.IPs[0]
+	}
+
+	pid, err := d.client.PID(info.ID)
+	if err != nil || pid <= 0 {
+		return
+	}
+	f.Process = procinfo.ListeningProcess(pid, f.ContainerPort, proto)
+}
@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/container"
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// listedDocker returns a dockerAPI that has already listed infos, and
+// whose daemon is down for any further request
+func listedDocker(t *testing.T, infos []container.Info) *dockerAPI {
+	t.Helper()
+	client := container.NewClient(filepath.Join(t.TempDir(), "docker.sock"))
+	return &dockerAPI{client: client, infos: infos, listed: true}
+}
+
+func TestResolveForwards(t *testing.T) {
+	infos := []container.Info{{
+		ID:    "4f6c1a3b2e9d",
+		Name:  "web",
+		Image: "nginx:1.27",
+		IPs:   []string{"172.17.0.2"},
+		Ports: []container.PortMapping{{HostIP: "0.0.0.0", HostPort: 8080, ContainerPort: 80, Protocol: "tcp"}},
+	}}
+
+	listeners := []model.Listener{
+		// Linux, with the userland proxy
+		{Port: 8080, Protocol: "tcp", Address: "0.0.0.0", PID: 10, Process: &model.Process{
+			Name:    "docker-proxy",
+			Cmdline: []string{"/usr/bin/docker-proxy", "-proto", "tcp", "-host-port", "8080", "-container-ip", "172.17.0.2", "-container-port", "80"},
+		}},
+		// Docker Desktop on macOS, with lsof's truncated command name
+		{Port: 8080, Protocol: "tcp", Address: "*", PID: 20, Process: &model.Process{
+			Name:    "com.docke",
+			Cmdline: []string{"/Applications/Docker.app/Contents/MacOS/com.docker.backend"},
+		}},
+		// A process inside the container, with host networking
+		{Port: 8080, Protocol: "tcp", Address: "0.0.0.0", PID: 30, Process: &model.Process{
+			Name:      "nginx",
+			Container: &model.Container{ID: "4f6c1a3b2e9d"},
+		}},
+		// An unrelated host process
+		{Port: 3000, Protocol: "tcp", Address: "127.0.0.1", PID: 40, Process: &model.Process{Name: "node"}},
+		// The kernel forwards, with no process
+		{Port: 8080, Protocol: "tcp", Address: "0.0.0.0"},
+	}
+
+	resolveForwards(listeners, listedDocker(t, infos))
+
+	want := []*model.Forward{
+		{Via: "docker-proxy", ContainerIP: "172.17.0.2", ContainerPort: 80},
+		{Via: "com.docker.backend", ContainerIP: "172.17.0.2", ContainerPort: 80},
+		nil,
+		nil,
+		nil,
+	}
+	for i, l := range listeners {
+		got := l.Forward
+		switch {
+		case got == nil && want[i] == nil:
+			continue
+		case got == nil || want[i] == nil:
+			t.Errorf("listener %d (%s): Forward = %+v, want %+v", i, l.Process.Name, got, want[i])
+			continue
+		}
+		if got.Via != want[i].Via || got.ContainerIP != want[i].ContainerIP || got.ContainerPort != want[i].ContainerPort {
+			t.Errorf("listener %d: Forward = %+v, want %+v", i, got, want[i])
+		}
+		if got.Container == nil || got.Container.Name != "web" || got.Container.Image != "nginx:1.27" {
+			t.Errorf("listener %d: Forward.Container = %+v, want web", i, got.Container)
+		}
+		if got.Process != nil {
+			t.Errorf("listener %d: Forward.Process = %+v, want none with the daemon down", i, got.Process)
+		}
+	}
+}
+
+func TestResolveForwardsWithoutDocker(t *testing.T) {
+	listeners := []model.Listener{
+		{Port: 8080, Protocol: "tcp", PID: 10, Process: &model.Process{
+			Name:    "docker-proxy",
+			Cmdline: []string{"docker-proxy", "-container-ip", "172.17.0.2", "-container-port", "80"},
+		}},
+		{Port: 8080, Protocol: "tcp", PID: 20, Process: &model.Process{Name: "com.docker.backend"}},
+	}
+	resolveForwards(listeners, nil)
+
+	if f := listeners[0].Forward; f == nil || f.ContainerIP != "172.17.0.2" || f.Container != nil {
+		t.Errorf("docker-proxy Forward = %+v, want its arguments only", f)
+	}
+	if f := listeners[1].Forward; f != nil {
+		t.Errorf("Forward = %+v without the Docker API, want nil", f)
+	}
+}
+
+// emptyScanner finds no listeners, so lookups fall back to the Docker API
+type emptyScanner struct{}
+
+func (emptyScanner) ListListeners() ([]model.Listener, error)       { return nil, nil }
+func (emptyScanner) GetPort(int) (*model.Listener, error)           { return nil, nil }
+func (emptyScanner) FindByPattern(string) ([]model.Listener, error) { return nil, nil }
+
+// serveDocker serves a Docker Engine API with one container publishing
+// 8080 on a unix socket, and returns the socket path
+func serveDocker(t *testing.T) string {
+	t.Helper()
+	// t.TempDir can exceed the 108-byte limit on socket paths
+	dir, err := os.MkdirTemp("", "docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"Id": "4f6c1a3b2e9d", "Names": ["/web"], "Image": "nginx",
+			"Ports": [{"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"}]}]`))
+	})
+	mux.HandleFunc("/containers/4f6c1a3b2e9d/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"State": {"Pid": 0}}`))
+	})
+
+	path := filepath.Join(dir, "docker.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Listener = ln
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return path
+}
+
+// TestDockerScansReuseConnection checks that repeated scans, as watch mode
+// makes, share one API connection instead of leaking one per scan
+func TestDockerScansReuseConnection(t *testing.T) {
+	opts := DefaultOptions()
+	opts.DockerSocket = serveDocker(t)
+	s := enrichScanner{Scanner: emptyScanner{}, opts: opts, docker: newDockerAPI(opts.DockerSocket)}
+
+	scan := func() {
+		l, err := s.GetPort(8080)
+		if err != nil || l == nil || l.Forward == nil || l.Forward.Container.Name != "web" {
+			t.Fatalf("GetPort(8080) = %+v, %v; want the port published by web", l, err)
+		}
+	}
+	scan()
+	before := runtime.NumGoroutine()
+	for range 50 {
+		scan()
+	}
+
+	// Let goroutines of finished requests exit
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before+2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before+2 {
+		t.Errorf("goroutines grew from %d to %d over 50 scans", before, after)
+	}
+}
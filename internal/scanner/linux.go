@@ -0,0 +1,177 @@
+//go:build linux
+
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/procinfo"
+)
+
+type LinuxScanner struct {
+	opts Options
+}
+
+func NewLinuxScanner(opts Options) *LinuxScanner {
+	return &LinuxScanner{opts: opts}
+}
+
+func newPlatformScanner(opts Options) (Scanner, error) {
+	return NewLinuxScanner(opts), nil
+}
+
+func (s *LinuxScanner) ListListeners() ([]model.Listener, error) {
+	sockets, err := s.sockets()
+	if err != nil {
+		return nil, err
+	}
+
+	listeners, _ := s.buildListeners(sockets, 0)
+	if s.opts.FetchStats {
+		stats := make(map[int]*model.ProcessStats)
+		for i := range listeners {
+			l := &listeners[i]
+			if l.PID <= 0 {
+				continue
+			}
+			if _, ok := stats[l.PID]; !ok {
+				stats[l.PID] = procinfo.Stats(l.PID)
+			}
+			l.Stats = stats[l.PID]
+		}
+	}
+	return listeners, nil
+}
+
+func (s *LinuxScanner) GetPort(port int) (*model.Listener, error) {
+	sockets, err := s.sockets()
+	if err != nil {
+		return nil, err
+	}
+
+	listeners, connections := s.buildListeners(sockets, port)
+	for i := range listeners {
+		l := &listeners[i]
+		if l.Port != port {
+			continue
+		}
+		l.Connections = connections
+		l.ConnectionCount = len(connections)
+		if l.PID > 0 {
+			l.Stats = procinfo.Stats(l.PID)
+		}
+		return l, nil
+	}
+	return nil, nil // Port not in use
+}
+
+func (s *LinuxScanner) FindByPattern(pattern string) ([]model.Listener, error) {
+	listeners, err := s.ListListeners()
+	if err != nil {
+		return nil, err
+	}
+	return matchPattern(listeners, pattern), nil
+}
+
+// sockets reads the socket tables of the enabled protocols. Only a missing
+// /proc/net/tcp is an error; the others are absent when, e.g., IPv6 is
+// disabled.
+func (s *LinuxScanner) sockets() ([]procinfo.Socket, error) {
+	var tables []string
+	if s.opts.IncludeTCP {
+		tables = append(tables, "tcp")
+	}
+	if s.opts.IncludeUDP {
+		tables = append(tables, "udp")
+	}
+	if s.opts.IncludeIPv6 {
+		for _, t := range tables {
+			tables = append(tables, t+"6")
+		}
+	}
+
+	var sockets []procinfo.Socket
+	for _, table := range tables {
+		entries, err := procinfo.ReadSockets(procinfo.Root, table)
+		if err != nil {
+			if table == "tcp" {
+				return nil, fmt.Errorf("reading socket table: %w", err)
+			}
+			continue
+		}
+		sockets = append(sockets, entries...)
+	}
+	return sockets, nil
+}
+
+// buildListeners turns the listening sockets into listeners, with the owning
+// processes and established connection counts. When port is set, it also
+// returns the established connections to that port.
+func (s *LinuxScanner) buildListeners(sockets []procinfo.Socket, port int) ([]model.Listener, []model.Connection) {
+	connCount := make(map[int]int) // port -> established connection count
+	var connections []model.Connection
+	inodes := make(map[string]bool)
+
+	for _, sk := range sockets {
+		switch {
+		case sk.Listening():
+			inodes[sk.Inode] = true
+		case sk.Protocol == "tcp" && sk.State == procinfo.StateEstablished:
+			connCount[sk.LocalPort]++
+			if port != 0 && sk.LocalPort == port {
+				connections = append(connections, model.Connection{
+					LocalAddr:  sk.LocalAddr,
+					LocalPort:  sk.LocalPort,
+					RemoteAddr: sk.RemoteAddr,
+					RemotePort: sk.RemotePort,
+					State:      "ESTABLISHED",
+				})
+			}
+		}
+	}
+	owners := procinfo.SocketOwners(inodes)
+
+	var listeners []model.Listener
+	processes := make(map[int]*model.Process)
+	seen := make(map[string]bool)
+	// A dual-stack server binds both 0.0.0.0 and ::, which shows as one
+	// listener, as it does on macOS. Other sockets on the same port, bound
+	// to other addresses or owned by other processes, stay separate.
+	wildcards := make(map[string]bool)
+
+	for _, sk := range sockets {
+		if !sk.Listening() || seen[sk.Inode] {
+			continue
+		}
+		seen[sk.Inode] = true
+		if sk.LocalAddr == "0.0.0.0" || sk.LocalAddr == "::" {
+			key := fmt.Sprintf("%d/%s/%d/%d", sk.LocalPort, sk.Protocol, owners[sk.Inode], sk.UID)
+			if wildcards[key] {
+				continue
+			}
+			wildcards[key] = true
+		}
+
+		l := model.Listener{
+			Port:            sk.LocalPort,
+			Protocol:        sk.Protocol,
+			Address:         sk.LocalAddr,
+			PID:             owners[sk.Inode],
+			ConnectionCount: connCount[sk.LocalPort],
+		}
+		if l.PID > 0 {
+			p, ok := processes[l.PID]
+			if !ok {
+				p, _ = procinfo.Process(l.PID)
+				processes[l.PID] = p
+			}
+			if p != nil {
+				proc := *p
+				l.Process = &proc
+			}
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, connections
+}
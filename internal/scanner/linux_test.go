@@ -0,0 +1,79 @@
+//go:build linux
+
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/procinfo"
+)
+
+// fakeProc points procinfo at a procfs where each pid holds the given
+// socket inodes
+func fakeProc(t *testing.T, owners map[int][]string) {
+	t.Helper()
+	root := t.TempDir()
+	for pid, inodes := range owners {
+		dir := filepath.Join(root, fmt.Sprint(pid), "fd")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for i, inode := range inodes {
+			if err := os.Symlink("socket:["+inode+"]", filepath.Join(dir, fmt.Sprint(i+3))); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	old := procinfo.Root
+	procinfo.Root = root
+	t.Cleanup(func() { procinfo.Root = old })
+}
+
+func listening(proto, addr string, port, uid int, inode string) procinfo.Socket {
+	state := procinfo.StateListen
+	if proto == "udp" {
+		state = procinfo.StateUnconnected
+	}
+	return procinfo.Socket{Protocol: proto, LocalAddr: addr, LocalPort: port, State: state, UID: uid, Inode: inode}
+}
+
+func TestBuildListeners(t *testing.T) {
+	fakeProc(t, map[int][]string{
+		20: {"1", "3", "4", "7"},
+		30: {"2", "5"},
+	})
+	sockets := []procinfo.Socket{
+		listening("tcp", "127.0.0.1", 8080, 1000, "1"),
+		listening("tcp", "::1", 8080, 1000, "2"), // Other address, other process
+		listening("tcp", "0.0.0.0", 3000, 1000, "3"),
+		listening("tcp", "::", 3000, 1000, "4"),        // Dual-stack pair: one listener
+		listening("tcp", "::", 3000, 1000, "5"),        // Same port with SO_REUSEPORT, other process
+		listening("tcp", "127.0.0.1", 8080, 1000, "1"), // Listed twice
+		listening("udp", "0.0.0.0", 3000, 1000, "7"),
+		{Protocol: "tcp", LocalAddr: "127.0.0.1", LocalPort: 8080, RemoteAddr: "127.0.0.1", RemotePort: 50000, State: procinfo.StateEstablished, Inode: "9"},
+	}
+
+	s := NewLinuxScanner(DefaultOptions())
+	listeners, connections := s.buildListeners(sockets, 8080)
+	var got []string
+	for _, l := range listeners {
+		got = append(got, fmt.Sprintf("%d/%s@%s pid %d conns %d", l.Port, l.Protocol, l.Address, l.PID, l.ConnectionCount))
+	}
+	want := []string{
+		"8080/tcp@127.0.0.1 pid 20 conns 1",
+		"8080/tcp@::1 pid 30 conns 1",
+		"3000/tcp@0.0.0.0 pid 20 conns 0",
+		"3000/tcp@:: pid 30 conns 0",
+		"3000/udp@0.0.0.0 pid 20 conns 0",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("buildListeners() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(connections) != 1 || connections[0].RemotePort != 50000 {
+		t.Errorf("connections = %v, want the one to port 8080", connections)
+	}
+}
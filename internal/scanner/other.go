@@ -0,0 +1,12 @@
+//go:build !darwin && !linux
+
+package scanner
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func newPlatformScanner(opts Options) (Scanner, error) {
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedPlatform, runtime.GOOS)
+}
@@ -2,8 +2,8 @@ package scanner
 
 import (
 	"errors"
-	"fmt"
-	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/tasnimzotder/portman/internal/model"
 )
@@ -25,6 +25,8 @@ type Options struct {
 	IncludeIPv6  bool
 	ResolveNames bool
 	FetchStats   bool
+	FetchContext bool   // Parent chain, TTY, cwd, terminal session and project of each process
+	DockerSocket string // Docker Engine API socket to name containers with, "" to not ask
 }
 
 func DefaultOptions() Options {
@@ -34,16 +36,55 @@ func DefaultOptions() Options {
 		IncludeIPv6:  true,
 		ResolveNames: false,
 		FetchStats:   false,
+		FetchContext: false,
+		DockerSocket: "",
 	}
 }
 
 func New(opts Options) (Scanner, error) {
-	switch runtime.GOOS {
-	case "linux":
-		return nil, ErrNotImplemented
-	case "darwin":
-		return NewDarwinScanner(opts), nil
-	default:
-		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPlatform, runtime.GOOS)
+	s, err := newPlatformScanner(opts)
+	if err != nil {
+		return nil, err
 	}
+	return enrichScanner{Scanner: s, opts: opts, docker: newDockerAPI(opts.DockerSocket)}, nil
+}
+
+// matchPattern returns the listeners whose port or PID equals pattern, or
+// whose process name, command or user contains it
+func matchPattern(listeners []model.Listener, pattern string) []model.Listener {
+	var matches []model.Listener
+	patternLower := strings.ToLower(pattern)
+
+	// Check if pattern is a port number
+	patternPort, isPort := strconv.Atoi(pattern)
+
+	for _, l := range listeners {
+		// Match by port number
+		if isPort == nil && l.Port == patternPort {
+			matches = append(matches, l)
+			continue
+		}
+
+		// Match by PID
+		if isPort == nil && l.PID == patternPort {
+			matches = append(matches, l)
+			continue
+		}
+
+		if l.Process == nil {
+			continue
+		}
+
+		name := strings.ToLower(l.Process.Name)
+		cmd := strings.ToLower(l.Process.Command)
+		user := strings.ToLower(l.Process.User)
+
+		if strings.Contains(name, patternLower) ||
+			strings.Contains(cmd, patternLower) ||
+			strings.Contains(user, patternLower) {
+			matches = append(matches, l)
+		}
+	}
+
+	return matches
 }
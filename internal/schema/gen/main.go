@@ -0,0 +1,72 @@
+// Command gen writes the JSON Schema files in docs/schema. It rewrites the
+// file of an unreleased version in place, but refuses to change a released
+// one, so changing a model type after a release without bumping its
+// document's version (e.g. model.SchemaVersion) fails. With -check it only
+// reports whether the files are up to date, for CI.
+//
+// Usage:
+//
+//	go run ./internal/schema/gen [-check] <dir>
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/tasnimzotder/portman/internal/schema"
+)
+
+func main() {
+	check := flag.Bool("check", false, "Fail if the schema files are missing or out of date instead of writing them")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: gen [-check] <dir>")
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *check); err != nil {
+		fmt.Fprintln(os.Stderr, "schema:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, check bool) error {
+	for _, doc := range schema.Documents {
+		data, err := doc.Generate()
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, doc.FileName())
+		existing, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if check {
+				return fmt.Errorf("%s is missing; run go generate ./internal/schema", path)
+			}
+		case err != nil:
+			return err
+		case bytes.Equal(existing, data):
+			continue
+		case doc.Frozen():
+			return fmt.Errorf("%s was released and can't change; increment %s (now %d)", path, doc.Const, doc.Version)
+		case check:
+			return fmt.Errorf("%s is out of date; run go generate ./internal/schema", path)
+		}
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+		fmt.Println("wrote", path)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package schema generates JSON Schema documents from the model types, so
+// the schema of portman's JSON output can't drift from the code.
+package schema
+
+//go:generate go run ./gen ../../docs/schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// draft is the JSON Schema dialect of the generated documents
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// baseURL prefixes the $id of every generated schema
+const baseURL = "https://github.com/tasnimzotder/portman/schema/"
+
+// Document is a named, generated schema. Each document is versioned on its
+// own, so a change to one doesn't bump the others. A version is frozen once
+// a release ships it; until then its file is regenerated in place, so one
+// release series adds at most one version.
+type Document struct {
+	Name     string // e.g. "scan-result"
+	Title    string
+	Type     reflect.Type
+	Version  int    // The schemaVersion the document's JSON carries
+	Released int    // The latest version shipped in a release (0 for none)
+	Const    string // The Go constant holding Version, for error messages
+}
+
+// Documents lists the schemas portman publishes. Released is set to Version
+// when tagging a release.
+var Documents = []Document{
+	{Name: "scan-result", Title: "portman scan result", Type: reflect.TypeFor[model.ScanResult](), Version: model.SchemaVersion, Released: 0, Const: "model.SchemaVersion"},
+	{Name: "error", Title: "portman error", Type: reflect.TypeFor[model.ErrorResult](), Version: model.ErrorSchemaVersion, Released: 0, Const: "model.ErrorSchemaVersion"},
+	{Name: "event", Title: "portman watch event", Type: reflect.TypeFor[model.Event](), Version: model.EventSchemaVersion, Released: 0, Const: "model.EventSchemaVersion"},
+}
+
+// Frozen reports whether the document's version has been released, so its
+// file must not change
+func (d Document) Frozen() bool {
+	return d.Version <= d.Released
+}
+
+// FileName returns the versioned file name of a document, e.g.
+// "scan-result.v1.json"
+func (d Document) FileName() string {
+	return fmt.Sprintf("%s.v%d.json", d.Name, d.Version)
+}
+
+// Generate returns the indented JSON Schema of a document
+func (d Document) Generate() ([]byte, error) {
+	g := &generator{defs: make(map[string]any)}
+
+	root := g.object(d.Type)
+	root["$schema"] = draft
+	root["$id"] = baseURL + d.FileName()
+	root["title"] = d.Title
+	if props, ok := root["properties"].(map[string]any); ok {
+		if version, ok := props["schemaVersion"].(map[string]any); ok {
+			version["const"] = d.Version
+		}
+	}
+	if len(g.defs) > 0 {
+		root["$defs"] = g.defs
+	}
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Lookup returns the document with the given name
+func Lookup(name string) (Document, bool) {
+	for _, d := range Documents {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Document{}, false
+}
+
+// Names returns the names of all documents
+func Names() []string {
+	names := make([]string, len(Documents))
+	for i, d := range Documents {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// generator builds schemas, placing nested structs under $defs
+type generator struct {
+	defs map[string]any
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// schemaFor returns the schema of a Go type
+func (g *generator) schemaFor(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaFor(t.Elem())
+	case reflect.Struct:
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = nil // Reserve the name for recursive types
+			g.defs[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// object returns the schema of a struct from its exported, JSON-tagged
+// fields. Fields without omitempty or omitzero are required; slices without
+// them may be null, as encoding/json writes nil slices that way. A
+// description tag becomes the property's description.
+func (g *generator) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		omitempty := strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero")
+
+		prop := g.schemaFor(field.Type)
+		if field.Type.Kind() == reflect.Slice && !omitempty {
+			prop["type"] = []string{"array", "null"}
+		}
+		if field.Type.Kind() == reflect.Pointer && !omitempty {
+			prop = map[string]any{"anyOf": []any{prop, map[string]any{"type": "null"}}}
+		}
+		if desc := field.Tag.Get("description"); desc != "" {
+			prop["description"] = desc
+		}
+		properties[name] = prop
+
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dir holds the committed schema files
+const dir = "../../docs/schema"
+
+func TestGenerateMatchesCommitted(t *testing.T) {
+	for _, doc := range Documents {
+		t.Run(doc.Name, func(t *testing.T) {
+			data, err := doc.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			committed, err := os.ReadFile(filepath.Join(dir, doc.FileName()))
+			if err != nil {
+				t.Fatalf("%v; run go generate ./internal/schema", err)
+			}
+			if !bytes.Equal(data, committed) && doc.Frozen() {
+				t.Errorf("%s was released and can't change; increment %s and run go generate ./internal/schema", doc.FileName(), doc.Const)
+			} else if !bytes.Equal(data, committed) {
+				t.Errorf("%s is out of date; run go generate ./internal/schema", doc.FileName())
+			}
+		})
+	}
+}
+
+func TestGenerateVersion(t *testing.T) {
+	for _, doc := range Documents {
+		data, err := doc.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schema struct {
+			ID         string `json:"$id"`
+			Properties struct {
+				SchemaVersion struct {
+					Const int `json:"const"`
+				} `json:"schemaVersion"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(schema.ID, "/"+doc.FileName()) {
+			t.Errorf("%s: $id = %s", doc.Name, schema.ID)
+		}
+		if schema.Properties.SchemaVersion.Const != doc.Version {
+			t.Errorf("%s: schemaVersion const = %d, want %d", doc.Name, schema.Properties.SchemaVersion.Const, doc.Version)
+		}
+	}
+}
+
+// TestVersionsFollowReleases checks that a document gains at most one
+// version between releases
+func TestVersionsFollowReleases(t *testing.T) {
+	for _, doc := range Documents {
+		if doc.Version < max(doc.Released, 1) || doc.Version > doc.Released+1 {
+			t.Errorf("%s: %s = %d, but the last release shipped version %d; use %d", doc.Name, doc.Const, doc.Version, doc.Released, doc.Released+1)
+		}
+	}
+}
+
+// TestVersionsDiffer checks that no two committed versions of a document
+// are the same schema, as a version is only added when the schema changes
+func TestVersionsDiffer(t *testing.T) {
+	for _, doc := range Documents {
+		files, err := filepath.Glob(filepath.Join(dir, doc.Name+".v*.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[string]string)
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := normalize(t, data)
+			if prev, ok := seen[body]; ok {
+				t.Errorf("%s and %s only differ in their version", filepath.Base(prev), filepath.Base(file))
+			}
+			seen[body] = file
+		}
+	}
+}
+
+// normalize drops the parts of a schema that name its version
+func normalize(t *testing.T, data []byte) string {
+	t.Helper()
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	delete(schema, "$id")
+	if props, ok := schema["properties"].(map[string]any); ok {
+		if version, ok := props["schemaVersion"].(map[string]any); ok {
+			delete(version, "const")
+		}
+	}
+	out, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range Names() {
+		if doc, ok := Lookup(name); !ok || doc.Name != name {
+			t.Errorf("Lookup(%q) = %+v, %v", name, doc, ok)
+		}
+	}
+	if _, ok := Lookup("missing"); ok {
+		t.Error("Lookup(missing) succeeded")
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/config"
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// RegistryEnv overrides the default registry path
+const RegistryEnv = "PORTMAN_REGISTRY"
+
+// registryEntry is one port from the team registry
+type registryEntry struct {
+	Port        int
+	Protocol    string // Empty for any
+	Name        string
+	Owner       string
+	Description string
+}
+
+// registry is the loaded team registry, consulted before /etc/services
+var registry []registryEntry
+
+// DefaultRegistryPath returns the registry path used when none is given:
+// $PORTMAN_REGISTRY, or services in the portman config directory
+func DefaultRegistryPath() string {
+	if path := os.Getenv(RegistryEnv); path != "" {
+		return path
+	}
+	if dir := config.Dir(); dir != "" {
+		return filepath.Join(dir, "services")
+	}
+	return ""
+}
+
+// LoadRegistry reads a team registry file, which maps ports to friendly
+// names, owners and descriptions:
+//
+//	# port[/protocol]  name  [owner]  [# description]
+//	8081      billing-api  team payments  # Invoices and payment webhooks
+//	5432/tcp  main-db      platform
+//
+// A missing file is only an error when required is set, i.e. when the path
+// was given explicitly.
+func LoadRegistry(path string, required bool) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading service registry: %w", err)
+	}
+	defer f.Close()
+
+	entries, err := parseRegistry(bufio.NewScanner(f), path)
+	if err != nil {
+		return err
+	}
+	registry = entries
+	return nil
+}
+
+func parseRegistry(scanner *bufio.Scanner, path string) ([]registryEntry, error) {
+	var out []registryEntry
+	for n := 1; scanner.Scan(); n++ {
+		line, description, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected a port and a name", path, n)
+		}
+
+		portStr, proto, _ := strings.Cut(fields[0], "/")
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("%s:%d: invalid port %q", path, n, fields[0])
+		}
+		proto = strings.ToLower(proto)
+		if proto != "" && proto != "tcp" && proto != "udp" {
+			return nil, fmt.Errorf("%s:%d: invalid protocol %q (use tcp or udp)", path, n, proto)
+		}
+
+		out = append(out, registryEntry{
+			Port:        port,
+			Protocol:    proto,
+			Name:        fields[1],
+			Owner:       strings.Join(fields[2:], " "),
+			Description: strings.TrimSpace(description),
+		})
+	}
+	return out, scanner.Err()
+}
+
+// Lookup returns the service on a port, preferring the team registry over
+// the system database, or nil when neither names it. The protocol may be
+// empty to match any.
+func Lookup(port int, protocol string) *model.Service {
+	for _, e := range registry {
+		if e.Port == port && (e.Protocol == "" || protocol == "" || e.Protocol == protocol) {
+			return &model.Service{
+				Name:        e.Name,
+				Owner:       e.Owner,
+				Description: e.Description,
+				Source:      "registry",
+			}
+		}
+	}
+
+	if name := Name(port, protocol); name != "" {
+		return &model.Service{Name: name, Source: "system"}
+	}
+	return nil
+}
+
+// Annotate sets the Service of each listener
+func Annotate(listeners []model.Listener) {
+	for i := range listeners {
+		listeners[i].Service = Lookup(listeners[i].Port, listeners[i].Protocol)
+	}
+}
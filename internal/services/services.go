@@ -0,0 +1,121 @@
+// Package services resolves between port numbers and service names using
+// a team registry file and the system services database (/etc/services).
+package services
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Path is the services database read on first use
+var Path = "/etc/services"
+
+// Entry is one service from the database
+type Entry struct {
+	Name     string
+	Port     int
+	Protocol string // "tcp" or "udp"
+	Aliases  []string
+}
+
+var (
+	loadOnce sync.Once
+	entries  []Entry
+)
+
+// load reads the services database once. A missing or unreadable file
+// leaves it empty.
+func load() []Entry {
+	loadOnce.Do(func() {
+		f, err := os.Open(Path)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		entries = parse(bufio.NewScanner(f))
+	})
+	return entries
+}
+
+// parse reads lines like "postgresql  5432/tcp  postgres  # PostgreSQL"
+func parse(scanner *bufio.Scanner) []Entry {
+	var out []Entry
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		portStr, proto, ok := strings.Cut(fields[1], "/")
+		if !ok {
+			continue
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port < 1 || port > 65535 {
+			continue
+		}
+
+		out = append(out, Entry{
+			Name:     fields[0],
+			Port:     port,
+			Protocol: strings.ToLower(proto),
+			Aliases:  fields[2:],
+		})
+	}
+	return out
+}
+
+// Ports returns the ports of a registry name, or of a service name or
+// alias, e.g. "postgresql" or "postgres", sorted and without duplicates
+func Ports(name string) []int {
+	var ports []int
+	seen := make(map[int]bool)
+	for _, e := range registry {
+		if strings.EqualFold(e.Name, name) && !seen[e.Port] {
+			seen[e.Port] = true
+			ports = append(ports, e.Port)
+		}
+	}
+	if len(ports) > 0 {
+		sort.Ints(ports)
+		return ports
+	}
+
+	for _, e := range load() {
+		if !e.matches(name) || seen[e.Port] {
+			continue
+		}
+		seen[e.Port] = true
+		ports = append(ports, e.Port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// Name returns the service name for a port and protocol, or "" when the
+// database has none. The protocol may be empty to match any.
+func Name(port int, protocol string) string {
+	for _, e := range load() {
+		if e.Port == port && (protocol == "" || e.Protocol == protocol) {
+			return e.Name
+		}
+	}
+	return ""
+}
+
+func (e Entry) matches(name string) bool {
+	if strings.EqualFold(e.Name, name) {
+		return true
+	}
+	for _, alias := range e.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
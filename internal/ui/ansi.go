@@ -9,15 +9,19 @@ const (
 	ClearLine   = "\033[2K"
 	HideCursor  = "\033[?25l"
 	ShowCursor  = "\033[?25h"
+	ClearToEnd  = "\033[J"
+	AltScreen   = "\033[?1049h"
+	MainScreen  = "\033[?1049l"
 
 	// Colors
-	Green  = "\033[32m"
-	Red    = "\033[31m"
-	Yellow = "\033[33m"
-	Cyan   = "\033[36m"
-	Bold   = "\033[1m"
-	Dim    = "\033[2m"
-	Reset  = "\033[0m"
+	Green   = "\033[32m"
+	Red     = "\033[31m"
+	Yellow  = "\033[33m"
+	Cyan    = "\033[36m"
+	Bold    = "\033[1m"
+	Dim     = "\033[2m"
+	Reverse = "\033[7m"
+	Reset   = "\033[0m"
 )
 
 // ClearAndReset clears the screen and moves cursor to top-left
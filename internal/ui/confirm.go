@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
 )
 
 // Confirm prompts the user for a yes/no confirmation.
@@ -47,3 +50,61 @@ func ConfirmWithDefault(prompt string, defaultYes bool) bool {
 
 	return input == "y" || input == "yes"
 }
+
+// KillSummary describes the process that will be killed for a listener,
+// as shown before asking for confirmation.
+func KillSummary(l model.Listener) string {
+	processName := "unknown"
+	userName := "unknown"
+	uptime := ""
+
+	if l.Process != nil {
+		processName = l.Process.Command
+		if processName == "" {
+			processName = l.Process.Name
+		}
+		userName = l.Process.User
+		if l.Process.UptimeSeconds > 0 {
+			uptime = (time.Duration(l.Process.UptimeSeconds) * time.Second).String()
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Kill process on port %d?\n", l.Port))
+	sb.WriteString(fmt.Sprintf("  Process: %s\n", processName))
+	sb.WriteString(fmt.Sprintf("  PID:     %d\n", l.PID))
+	sb.WriteString(fmt.Sprintf("  User:    %s\n", userName))
+	if uptime != "" {
+		sb.WriteString(fmt.Sprintf("  Uptime:  %s\n", uptime))
+	}
+	return sb.String()
+}
+
+// KillSummaryAll describes the processes that will be killed for several
+// listeners, one line per port, as shown before a single confirmation.
+func KillSummaryAll(listeners []model.Listener) string {
+	pids := make(map[int]bool)
+	for _, l := range listeners {
+		pids[l.PID] = true
+	}
+
+	var sb strings.Builder
+	noun := "processes"
+	if len(pids) == 1 {
+		noun = "process"
+	}
+	sb.WriteString(fmt.Sprintf("Kill %d %s on %d ports?\n", len(pids), noun, len(listeners)))
+	for _, l := range listeners {
+		processName := "unknown"
+		userName := "unknown"
+		if l.Process != nil {
+			processName = l.Process.Command
+			if processName == "" {
+				processName = l.Process.Name
+			}
+			userName = l.Process.User
+		}
+		sb.WriteString(fmt.Sprintf("  %-6d PID %-7d %-10s %s\n", l.Port, l.PID, userName, processName))
+	}
+	return sb.String()
+}
@@ -0,0 +1,306 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/output"
+	"github.com/tasnimzotder/portman/internal/scanner"
+	"github.com/tasnimzotder/portman/internal/services"
+)
+
+// panelWidth is the outer width of a dashboard panel, borders included
+const panelWidth = 32
+
+// DashboardConfig holds configuration for the multi-port dashboard
+type DashboardConfig struct {
+	Scanner  scanner.Scanner
+	Ports    []int
+	Hosts    map[int]string // Host of each port given as host:port
+	Interval time.Duration
+}
+
+// DashboardState tracks the current state of the multi-port dashboard
+type DashboardState struct {
+	config        DashboardConfig
+	current       map[int]*model.Listener
+	errs          map[int]error // Ports whose last lookup failed
+	downSince     map[int]time.Time
+	restarts      *restartTracker
+	isFirstRender bool
+}
+
+func newDashboardState(cfg DashboardConfig) *DashboardState {
+	return &DashboardState{
+		config:        cfg,
+		current:       make(map[int]*model.Listener),
+		errs:          make(map[int]error),
+		downSince:     make(map[int]time.Time),
+		restarts:      newRestartTracker(),
+		isFirstRender: true,
+	}
+}
+
+// RunDashboard watches several ports at once, one panel per port
+func RunDashboard(cfg DashboardConfig) error {
+	state := newDashboardState(cfg)
+
+	sess := startSession()
+	defer sess.close()
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	state.update()
+	state.render()
+
+	for {
+		select {
+		case <-sess.signals:
+			return nil
+		case <-sess.resize:
+			state.render()
+		case key := <-sess.keys:
+			if key == 'q' || key == 'Q' {
+				return nil
+			}
+		case <-ticker.C:
+			state.update()
+			state.render()
+		}
+	}
+}
+
+// StreamDashboardEvents is the multi-port equivalent of StreamEvents
+func StreamDashboardEvents(cfg DashboardConfig, w io.Writer) error {
+	return streamLoop(cfg.Interval, w, func() (map[listenerKey]model.Listener, error) {
+		var listeners []model.Listener
+		for _, port := range cfg.Ports {
+			l, err := cfg.Scanner.GetPort(port)
+			if err != nil {
+				return nil, err
+			}
+			if l != nil {
+				listeners = append(listeners, *l)
+			}
+		}
+		return listenerMap(listeners), nil
+	})
+}
+
+// scanPorts looks up each port, mapping ports that aren't in use to nil and
+// returning the errors of lookups that failed apart
+func scanPorts(s scanner.Scanner, ports []int) (map[int]*model.Listener, map[int]error) {
+	current := make(map[int]*model.Listener, len(ports))
+	errs := make(map[int]error)
+	for _, port := range ports {
+		l, err := s.GetPort(port)
+		if err != nil {
+			errs[port] = err
+			continue
+		}
+		current[port] = l
+	}
+	return current, errs
+}
+
+// update fetches every port and tracks downtime and restarts. A port whose
+// lookup failed is neither up nor down, so its downtime and restarts carry
+// over to the next scan that succeeds.
+func (s *DashboardState) update() {
+	now := time.Now()
+	s.current, s.errs = scanPorts(s.config.Scanner, s.config.Ports)
+
+	for _, port := range s.config.Ports {
+		if s.errs[port] != nil {
+			continue
+		}
+		l := s.current[port]
+		if l == nil {
+			if _, ok := s.downSince[port]; !ok {
+				s.downSince[port] = now
+			}
+			continue
+		}
+		delete(s.downSince, port)
+		s.restarts.observe(*l, now)
+	}
+}
+
+// up returns the number of watched ports that are listening
+func (s *DashboardState) up() int {
+	n := 0
+	for _, port := range s.config.Ports {
+		if s.current[port] != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// render draws the summary and a grid of port panels
+func (s *DashboardState) render() {
+	f := newFrame()
+
+	f.add("%s%sportman watch%s  %sRefresh: %s  Press 'q' to quit%s", Bold, Cyan, Reset, Dim, s.config.Interval, Reset)
+	f.rule()
+
+	// Summary
+	up, total := s.up(), len(s.config.Ports)
+	var down, failed []string
+	for _, port := range s.config.Ports {
+		switch {
+		case s.errs[port] != nil:
+			failed = append(failed, s.label(port))
+		case s.current[port] == nil:
+			down = append(down, s.label(port))
+		}
+	}
+	switch {
+	case up == total:
+		f.add("%s%s● Stack up%s  %sall %d ports listening%s", Bold, Green, Reset, Dim, total, Reset)
+	case len(down) > 0:
+		line := fmt.Sprintf("%s%s✖ Stack down%s  %d/%d up  %sdown: %s%s", Bold, Red, Reset, up, total, Red, strings.Join(down, ", "), Reset)
+		if len(failed) > 0 {
+			line += fmt.Sprintf("  %serror: %s%s", Yellow, strings.Join(failed, ", "), Reset)
+		}
+		f.add("%s", line)
+	default:
+		f.add("%s%s⚠ Stack unknown%s  %d/%d up  %serror: %s%s", Bold, Yellow, Reset, up, total, Yellow, strings.Join(failed, ", "), Reset)
+	}
+	f.blank()
+
+	// Panels, laid out left to right as the width allows
+	perRow := max(1, (f.width+1)/(panelWidth+1))
+	now := time.Now()
+	for start := 0; start < len(s.config.Ports); start += perRow {
+		end := min(start+perRow, len(s.config.Ports))
+
+		var panels [][]string
+		for _, port := range s.config.Ports[start:end] {
+			panels = append(panels, s.panel(port, now))
+		}
+
+		for line := range panels[0] {
+			parts := make([]string, len(panels))
+			for i, p := range panels {
+				parts[i] = p[line]
+			}
+			f.add("%s", strings.Join(parts, " "))
+		}
+	}
+
+	f.draw(s.isFirstRender)
+	s.isFirstRender = false
+}
+
+// panel renders the box for one port
+func (s *DashboardState) panel(port int, now time.Time) []string {
+	l := s.current[port]
+	inner := panelWidth - 4
+
+	if err := s.errs[port]; err != nil {
+		msg, _, _ := strings.Cut(err.Error(), "\n")
+		return box(Yellow, fmt.Sprintf("%s %s%s⚠ ERR%s", s.panelPort(port, services.Lookup(port, "")), Bold, Yellow, Reset), wrapText(msg, inner, 3), inner)
+	}
+
+	if l == nil {
+		since := "not seen yet"
+		if s.seen(port) {
+			since = "down for " + shortDuration(now.Sub(s.downSince[port]))
+		}
+		return box(Red, fmt.Sprintf("%s %s%s✖ DOWN%s", s.panelPort(port, services.Lookup(port, "")), Bold, Red, Reset), []string{
+			fmt.Sprintf("%s%s%s", Red, since, Reset),
+			"",
+			"",
+		}, inner)
+	}
+
+	name := processName(*l)
+	if name == "" {
+		name = "unknown"
+	}
+	owner := fmt.Sprintf("%s (pid %d)", name, l.PID)
+	if n := s.restarts.count(port); n > 0 {
+		owner += fmt.Sprintf(" %s↻%d%s", Yellow, n, Reset)
+	}
+
+	conns := fmt.Sprintf("conns %d", l.ConnectionCount)
+	if l.Process != nil && l.Process.UptimeSeconds > 0 {
+		conns += "  up " + output.FormatDuration(l.Process.UptimeSeconds)
+	}
+
+	stats := Dim + "no stats" + Reset
+	if l.Stats != nil {
+		stats = fmt.Sprintf("mem %s  cpu %.1f%%", output.FormatBytes(l.Stats.MemoryRSS), l.Stats.CPUPercent)
+	}
+
+	color := Green
+	if _, _, flapping := s.restarts.flapping(port, now); flapping {
+		color = Yellow
+	}
+
+	return box(color, fmt.Sprintf("%s %s● UP%s", s.panelPort(port, l.Service), color, Reset), []string{owner, conns, stats}, inner)
+}
+
+// label returns a port as it was given, with its host if any
+func (s *DashboardState) label(port int) string {
+	if host := s.config.Hosts[port]; host != "" {
+		return net.JoinHostPort(host, strconv.Itoa(port))
+	}
+	return strconv.Itoa(port)
+}
+
+// panelPort labels a panel with its port and, when known, its service,
+// cut to leave room for the status
+func (s *DashboardState) panelPort(port int, service *model.Service) string {
+	if service == nil {
+		return s.label(port)
+	}
+	return fmt.Sprintf("%s %s%s%s", s.label(port), Dim, fitLine(service.Name, 12), Reset)
+}
+
+// seen reports whether a port has been up at any point this session
+func (s *DashboardState) seen(port int) bool {
+	return s.restarts.seen(port)
+}
+
+// wrapText breaks text into exactly n lines of at most width columns at
+// spaces, cutting what doesn't fit in the last one
+func wrapText(text string, width, n int) []string {
+	lines := make([]string, n)
+	i := 0
+	for _, word := range strings.Fields(text) {
+		switch {
+		case lines[i] == "":
+			lines[i] = word
+		case i < n-1 && visibleWidth(lines[i])+1+visibleWidth(word) > width:
+			i++
+			lines[i] = word
+		default:
+			lines[i] += " " + word
+		}
+	}
+	return lines
+}
+
+// box draws a bordered panel with a title and fixed-width content lines
+func box(color, title string, lines []string, inner int) []string {
+	title = " " + title + " "
+	fill := max(0, inner+1-visibleWidth(title))
+	out := []string{color + "┌─" + Reset + title + color + strings.Repeat("─", fill) + "┐" + Reset}
+
+	for _, line := range lines {
+		line = fitLine(line, inner)
+		pad := strings.Repeat(" ", max(0, inner-visibleWidth(line)))
+		out = append(out, color+"│"+Reset+" "+line+pad+" "+color+"│"+Reset)
+	}
+
+	out = append(out, color+"└"+strings.Repeat("─", inner+2)+"┘"+Reset)
+	return out
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+func TestDashboardShowsScanErrors(t *testing.T) {
+	boom := errors.New("lsof: permission denied\nmore detail")
+	up := []model.Listener{listener(8080, "tcp", "127.0.0.1", 20, "node", 0)}
+	s := newDashboardState(DashboardConfig{Ports: []int{8080}, Scanner: &fakeScanner{scans: []fakeScan{
+		{err: boom},
+		{listeners: up},
+		{err: boom},
+		{},
+	}}})
+
+	tests := []struct {
+		shown []string
+		gone  []string
+	}{
+		{[]string{"⚠ ERR", "lsof: permission denied", "Stack unknown", "error: 8080"}, []string{"DOWN", "more detail"}},
+		{[]string{"● UP", "Stack up"}, []string{"ERR"}},
+		{[]string{"⚠ ERR", "Stack unknown"}, []string{"DOWN", "Stack down"}},
+		{[]string{"✖ DOWN", "down for", "Stack down"}, []string{"ERR"}}, // Up before the error
+	}
+	for i, tt := range tests {
+		s.update()
+		out := captureStdout(t, s.render)
+		for _, want := range tt.shown {
+			if !strings.Contains(out, want) {
+				t.Errorf("scan %d: %q not shown", i, want)
+			}
+		}
+		for _, unwanted := range tt.gone {
+			if strings.Contains(out, unwanted) {
+				t.Errorf("scan %d: %q shown", i, unwanted)
+			}
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	got := wrapText("open /proc/net/tcp: permission denied by policy", 16, 3)
+	want := []string{"open", "/proc/net/tcp:", "permission denied by policy"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("wrapText = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// DefaultLogLines is the default number of events kept in the watch log panel
+const DefaultLogLines = 8
+
+// eventLog keeps a scrollback of port changes for the watch view and
+// optionally appends every entry to a file
+type eventLog struct {
+	entries []model.Event
+	max     int
+	file    *os.File
+}
+
+// newEventLog creates an event log holding up to max entries. If path is not
+// empty, entries are also appended to that file.
+func newEventLog(max int, path string) (*eventLog, error) {
+	l := &eventLog{max: max}
+
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open event log: %w", err)
+		}
+		l.file = f
+	}
+
+	return l, nil
+}
+
+// record adds lifecycle events to the log. Connection count changes are too
+// noisy for a scrollback and are left out.
+func (l *eventLog) record(events []model.Event) {
+	if l == nil {
+		return
+	}
+
+	for _, e := range events {
+		if e.Type == EventConnsChanged {
+			continue
+		}
+
+		if l.file != nil {
+			fmt.Fprintf(l.file, "%s %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), formatEvent(e))
+		}
+
+		if l.max > 0 {
+			l.entries = append(l.entries, e)
+			if len(l.entries) > l.max {
+				l.entries = l.entries[len(l.entries)-l.max:]
+			}
+		}
+	}
+}
+
+// Close closes the log file, if any
+func (l *eventLog) Close() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// formatEvent renders an event as a compact log entry, e.g.
+// "+3000 node (pid 812)" or "-5432 postgres"
+func formatEvent(e model.Event) string {
+	switch e.Type {
+	case EventAdded:
+		return fmt.Sprintf("+%d %s (pid %d)", e.Port, e.Process, e.PID)
+	case EventRemoved:
+		return fmt.Sprintf("-%d %s", e.Port, e.Process)
+	case EventRestarted:
+		return fmt.Sprintf("↻%d %s (pid %d → %d)", e.Port, e.Process, e.PreviousPID, e.PID)
+	case EventOwnerChanged:
+		return fmt.Sprintf("~%d %s → %s (pid %d)", e.Port, e.PreviousProcess, e.Process, e.PID)
+	default:
+		return fmt.Sprintf("%s %d", e.Type, e.Port)
+	}
+}
+
+// eventColor returns the color used for an event in the log panel
+func eventColor(e model.Event) string {
+	switch e.Type {
+	case EventAdded:
+		return Green
+	case EventRemoved:
+		return Red
+	default:
+		return Yellow
+	}
+}
+
+// render adds the scrollback panel below the port table
+func (l *eventLog) render(f *frame) {
+	if l == nil || l.max == 0 {
+		return
+	}
+
+	f.blank()
+	f.add("%sEvents%s", Bold, Reset)
+	if len(l.entries) == 0 {
+		f.add("  %sNo changes yet%s", Dim, Reset)
+		return
+	}
+
+	for _, e := range l.entries {
+		f.add("  %s%s%s %s%s%s",
+			Dim, e.Time.Local().Format(time.TimeOnly), Reset,
+			eventColor(e), formatEvent(e), Reset)
+	}
+}
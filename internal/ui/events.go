@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// Event types, the Type of a model.Event
+const (
+	EventAdded        = "added"
+	EventRemoved      = "removed"
+	EventOwnerChanged = "owner_changed"
+	EventRestarted    = "restarted"
+	EventConnsChanged = "conns_changed"
+	EventError        = "error"
+)
+
+// listenerKey identifies a listener across scans. A port can have several
+// listeners, e.g. TCP and UDP, or one per address.
+type listenerKey struct {
+	Port     int
+	Protocol string
+	Address  string
+}
+
+func keyOf(l model.Listener) listenerKey {
+	return listenerKey{Port: l.Port, Protocol: l.Protocol, Address: l.Address}
+}
+
+// less orders keys by port, protocol, then address
+func (k listenerKey) less(o listenerKey) bool {
+	if k.Port != o.Port {
+		return k.Port < o.Port
+	}
+	if k.Protocol != o.Protocol {
+		return k.Protocol < o.Protocol
+	}
+	return k.Address < o.Address
+}
+
+// listenerMap keys listeners for diffListeners
+func listenerMap(listeners []model.Listener) map[listenerKey]model.Listener {
+	m := make(map[listenerKey]model.Listener, len(listeners))
+	for _, l := range listeners {
+		m[keyOf(l)] = l
+	}
+	return m
+}
+
+// newEvent builds an event populated from a listener
+func newEvent(t time.Time, typ string, l model.Listener) model.Event {
+	count := l.ConnectionCount
+	return model.Event{
+		SchemaVersion:   model.EventSchemaVersion,
+		Time:            t,
+		Type:            typ,
+		Port:            l.Port,
+		Protocol:        l.Protocol,
+		Address:         l.Address,
+		Service:         l.Service.Label(),
+		PID:             l.PID,
+		Process:         processName(l),
+		ConnectionCount: &count,
+	}
+}
+
+// processName returns the best available name for a listener's process
+func processName(l model.Listener) string {
+	if l.Process == nil {
+		return ""
+	}
+	if l.Process.Name != "" {
+		return l.Process.Name
+	}
+	return l.Process.Command
+}
+
+// diffListeners compares two scans keyed by listener and returns the
+// changes, ordered by port, protocol, and address
+func diffListeners(prev, cur map[listenerKey]model.Listener, t time.Time) []model.Event {
+	var events []model.Event
+
+	for key, l := range cur {
+		old, exists := prev[key]
+		if !exists {
+			events = append(events, newEvent(t, EventAdded, l))
+			continue
+		}
+
+		if change := ownerChange(old, l); change != "" {
+			e := newEvent(t, change, l)
+			e.PreviousPID = old.PID
+			e.PreviousProcess = processName(old)
+			events = append(events, e)
+		}
+
+		if old.ConnectionCount != l.ConnectionCount {
+			e := newEvent(t, EventConnsChanged, l)
+			prevCount := old.ConnectionCount
+			e.PreviousConnectionCount = &prevCount
+			events = append(events, e)
+		}
+	}
+
+	for key, l := range prev {
+		if _, exists := cur[key]; !exists {
+			events = append(events, newEvent(t, EventRemoved, l))
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a := listenerKey{Port: events[i].Port, Protocol: events[i].Protocol, Address: events[i].Address}
+		b := listenerKey{Port: events[j].Port, Protocol: events[j].Protocol, Address: events[j].Address}
+		return a.less(b)
+	})
+
+	return events
+}
+
+// StreamEvents writes one JSON object per line to w for every change in the
+// listening ports. The first scan reports every existing listener as added.
+func StreamEvents(cfg WatchConfig, w io.Writer) error {
+	state := newWatchState(cfg)
+
+	return streamLoop(cfg.Interval, w, func() (map[listenerKey]model.Listener, error) {
+		if err := state.update(); err != nil {
+			return nil, err
+		}
+		return state.previous, nil
+	})
+}
+
+// StreamPortEvents is the single-port equivalent of StreamEvents
+func StreamPortEvents(cfg WatchPortConfig, w io.Writer) error {
+	return streamLoop(cfg.Interval, w, func() (map[listenerKey]model.Listener, error) {
+		listener, err := cfg.Scanner.GetPort(cfg.Port)
+		if err != nil {
+			return nil, err
+		}
+		if listener == nil {
+			return listenerMap(nil), nil
+		}
+		return listenerMap([]model.Listener{*listener}), nil
+	})
+}
+
+// streamLoop polls scan at the given interval and encodes the resulting
+// events as NDJSON until interrupted or the writer fails. A failed scan is
+// reported as an error event, once until the error changes or a scan
+// succeeds, and polling goes on.
+func streamLoop(interval time.Duration, w io.Writer, scan func() (map[listenerKey]model.Listener, error)) error {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	enc := json.NewEncoder(w)
+	previous := make(map[listenerKey]model.Listener)
+	var lastErr string
+
+	emit := func() error {
+		current, err := scan()
+		if err != nil {
+			if err.Error() == lastErr {
+				return nil
+			}
+			lastErr = err.Error()
+			return enc.Encode(model.Event{
+				SchemaVersion: model.EventSchemaVersion,
+				Time:          time.Now().UTC(),
+				Type:          EventError,
+				Message:       lastErr,
+			})
+		}
+		lastErr = ""
+		for _, e := range diffListeners(previous, current, time.Now().UTC()) {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		previous = current
+		return nil
+	}
+
+	if err := emit(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sigChan:
+			return nil
+		case <-ticker.C:
+			if err := emit(); err != nil {
+				return err
+			}
+		}
+	}
+}
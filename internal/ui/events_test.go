@@ -0,0 +1,185 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+func listener(port int, proto, addr string, pid int, name string, conns int) model.Listener {
+	return model.Listener{
+		Port:            port,
+		Protocol:        proto,
+		Address:         addr,
+		PID:             pid,
+		Process:         &model.Process{PID: pid, Name: name},
+		ConnectionCount: conns,
+	}
+}
+
+// eventSummary renders events as "type port/protocol@address" for comparison
+func eventSummary(events []model.Event) string {
+	var parts []string
+	for _, e := range events {
+		parts = append(parts, fmt.Sprintf("%s %d/%s@%s", e.Type, e.Port, e.Protocol, e.Address))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestDiffListeners(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		prev, cur []model.Listener
+		want      string
+	}{
+		{
+			name: "tcp and udp on one port",
+			cur: []model.Listener{
+				listener(53, "udp", "0.0.0.0", 10, "dnsmasq", 0),
+				listener(53, "tcp", "0.0.0.0", 10, "dnsmasq", 0),
+			},
+			want: "added 53/tcp@0.0.0.0, added 53/udp@0.0.0.0",
+		},
+		{
+			name: "udp goes away, tcp stays",
+			prev: []model.Listener{
+				listener(53, "tcp", "0.0.0.0", 10, "dnsmasq", 0),
+				listener(53, "udp", "0.0.0.0", 10, "dnsmasq", 0),
+			},
+			cur:  []model.Listener{listener(53, "tcp", "0.0.0.0", 10, "dnsmasq", 0)},
+			want: "removed 53/udp@0.0.0.0",
+		},
+		{
+			name: "one port, two addresses, two owners",
+			prev: []model.Listener{
+				listener(8080, "tcp", "127.0.0.1", 20, "node", 0),
+				listener(8080, "tcp", "::1", 30, "python3", 0),
+			},
+			cur: []model.Listener{
+				listener(8080, "tcp", "127.0.0.1", 20, "node", 2),
+				listener(8080, "tcp", "::1", 30, "python3", 0),
+			},
+			want: "conns_changed 8080/tcp@127.0.0.1",
+		},
+		{
+			name: "owner change and restart",
+			prev: []model.Listener{
+				listener(3000, "tcp", "::", 40, "node", 0),
+				listener(5432, "tcp", "::", 50, "postgres", 0),
+			},
+			cur: []model.Listener{
+				listener(3000, "tcp", "::", 41, "bun", 0),
+				listener(5432, "tcp", "::", 51, "postgres", 0),
+			},
+			want: "owner_changed 3000/tcp@::, restarted 5432/tcp@::",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eventSummary(diffListeners(listenerMap(tt.prev), listenerMap(tt.cur), now))
+			if got != tt.want {
+				t.Errorf("diffListeners() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// limitWriter fails once it has taken n writes
+type limitWriter struct {
+	bytes.Buffer
+	n int
+}
+
+var errFull = errors.New("writer full")
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	w.Buffer.Write(p)
+	if w.n--; w.n <= 0 {
+		return len(p), errFull
+	}
+	return len(p), nil
+}
+
+func TestStreamLoopReportsScanErrors(t *testing.T) {
+	boom := errors.New("lsof: permission denied")
+	up := listenerMap([]model.Listener{listener(8080, "tcp", "127.0.0.1", 20, "node", 0)})
+	scans := []struct {
+		listeners map[listenerKey]model.Listener
+		err       error
+	}{
+		{err: boom},
+		{err: boom}, // Reported once
+		{listeners: up},
+		{err: boom}, // Reported again after a good scan
+	}
+
+	w := &limitWriter{n: 3}
+	calls := 0
+	err := streamLoop(time.Millisecond, w, func() (map[listenerKey]model.Listener, error) {
+		s := scans[min(calls, len(scans)-1)]
+		calls++
+		return s.listeners, s.err
+	})
+	if !errors.Is(err, errFull) {
+		t.Fatalf("streamLoop() = %v, want the writer's error", err)
+	}
+
+	var types, messages []string
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		var e model.Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("bad event %q: %v", line, err)
+		}
+		if e.SchemaVersion != model.EventSchemaVersion {
+			t.Errorf("event %s: schemaVersion = %d, want %d", line, e.SchemaVersion, model.EventSchemaVersion)
+		}
+		if e.Type == EventError && (strings.Contains(line, `"port"`) || strings.Contains(line, `"connectionCount"`)) {
+			t.Errorf("error event %s has listener fields", line)
+		}
+		if e.Type == EventAdded && (e.ConnectionCount == nil || *e.ConnectionCount != 0) {
+			t.Errorf("added event %s should carry a connectionCount of 0", line)
+		}
+		types = append(types, e.Type)
+		messages = append(messages, e.Message)
+	}
+	if got := strings.Join(types, ","); got != "error,added,error" {
+		t.Errorf("event types = %s, want error,added,error", got)
+	}
+	if messages[0] != boom.Error() {
+		t.Errorf("error message = %q, want %q", messages[0], boom.Error())
+	}
+}
+
+func TestRestartTrackerPerListener(t *testing.T) {
+	tracker := newRestartTracker()
+	now := time.Now()
+
+	// Two owners of one port, observed alternately, are not restarts
+	for range 3 {
+		for _, l := range []model.Listener{
+			listener(8080, "tcp", "127.0.0.1", 20, "node", 0),
+			listener(8080, "tcp", "::1", 30, "python3", 0),
+		} {
+			if kind, _ := tracker.observe(l, now); kind != "" {
+				t.Fatalf("observe(%s) = %s, want no change", l.Address, kind)
+			}
+		}
+	}
+	if !tracker.seen(8080) || tracker.seen(8081) {
+		t.Error("seen() should report only port 8080")
+	}
+
+	if kind, _ := tracker.observe(listener(8080, "tcp", "::1", 31, "python3", 0), now); kind != EventRestarted {
+		t.Errorf("observe() = %q, want %q", kind, EventRestarted)
+	}
+	if got := tracker.count(8080); got != 1 {
+		t.Errorf("count(8080) = %d, want 1", got)
+	}
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// maxSamples bounds a series when history is kept for the whole session
+const maxSamples = 10000
+
+// sparkBlocks are the glyphs used to draw sparklines, lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+// series is a rolling history of one metric
+type series struct {
+	samples []sample
+}
+
+// add records a value and drops samples older than window (0 keeps the
+// whole session, up to maxSamples)
+func (s *series) add(now time.Time, value float64, window time.Duration) {
+	s.samples = append(s.samples, sample{at: now, value: value})
+
+	drop := 0
+	if window > 0 {
+		for drop < len(s.samples) && now.Sub(s.samples[drop].at) > window {
+			drop++
+		}
+	}
+	drop = max(drop, len(s.samples)-maxSamples)
+	if drop > 0 {
+		s.samples = append(s.samples[:0], s.samples[drop:]...)
+	}
+}
+
+// summary returns the minimum, maximum, and average of the series
+func (s *series) summary() (float64, float64, float64) {
+	if len(s.samples) == 0 {
+		return 0, 0, 0
+	}
+
+	lo, hi, sum := math.Inf(1), math.Inf(-1), 0.0
+	for _, p := range s.samples {
+		lo = math.Min(lo, p.value)
+		hi = math.Max(hi, p.value)
+		sum += p.value
+	}
+	return lo, hi, sum / float64(len(s.samples))
+}
+
+// sparkline draws the series in at most width characters. Longer histories
+// are averaged into buckets so the whole window stays visible.
+func (s *series) sparkline(width int) string {
+	if len(s.samples) == 0 || width <= 0 {
+		return ""
+	}
+
+	values := make([]float64, len(s.samples))
+	for i, p := range s.samples {
+		values[i] = p.value
+	}
+	if len(values) > width {
+		values = resample(values, width)
+	}
+
+	lo, hi, _ := s.summary()
+	var sb strings.Builder
+	for _, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		sb.WriteRune(sparkBlocks[level])
+	}
+	return sb.String()
+}
+
+// resample averages values into n equal buckets
+func resample(values []float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range n {
+		start := i * len(values) / n
+		end := max((i+1)*len(values)/n, start+1)
+		sum := 0.0
+		for _, v := range values[start:end] {
+			sum += v
+		}
+		out[i] = sum / float64(end-start)
+	}
+	return out
+}
+
+// portHistory holds the per-metric history of the single-port watch view
+type portHistory struct {
+	window      time.Duration
+	connections series
+	memory      series
+	cpu         series
+	fds         series
+	threads     series
+}
+
+func newPortHistory(window time.Duration) *portHistory {
+	return &portHistory{window: window}
+}
+
+// record adds the listener's current metrics to the history
+func (h *portHistory) record(l model.Listener, now time.Time) {
+	h.connections.add(now, float64(l.ConnectionCount), h.window)
+	if l.Stats == nil {
+		return
+	}
+	h.memory.add(now, float64(l.Stats.MemoryRSS), h.window)
+	h.cpu.add(now, l.Stats.CPUPercent, h.window)
+	h.fds.add(now, float64(l.Stats.FDCount), h.window)
+	h.threads.add(now, float64(l.Stats.ThreadCount), h.window)
+}
+
+// maxSparkWidth keeps sparklines readable on very wide terminals
+const maxSparkWidth = 60
+
+// sparkSuffix renders a sparkline with min/max/avg to append to a line that
+// already uses the given number of columns. Values are formatted with
+// format. It returns "" until there are at least two samples, or when the
+// line has no room left.
+func sparkSuffix(s *series, used, width int, format func(float64) string) string {
+	if len(s.samples) < 2 {
+		return ""
+	}
+
+	lo, hi, avg := s.summary()
+	stats := "  min " + format(lo) + "  max " + format(hi) + "  avg " + format(avg)
+
+	sparkWidth := min(width-used-2-len([]rune(stats)), maxSparkWidth)
+	if sparkWidth < 4 {
+		return ""
+	}
+
+	return "  " + Cyan + s.sparkline(sparkWidth) + Reset + Dim + stats + Reset
+}
@@ -0,0 +1,237 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tasnimzotder/portman/internal/kill"
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/output"
+)
+
+// sortOrders lists the sort keys cycled through with 's'
+var sortOrders = []string{"port", "pid", "user", "conns", "uptime"}
+
+// handleKey applies a keypress to the watch view. It returns true when the
+// session should end.
+func (s *WatchState) handleKey(sess *session, key rune) bool {
+	s.message = ""
+
+	if s.filtering {
+		s.editFilter(key)
+		return false
+	}
+
+	switch key {
+	case 'q', 'Q':
+		return true
+	case KeyUp, 'k':
+		s.moveSelection(-1)
+	case KeyDown, 'j':
+		s.moveSelection(1)
+	case KeyPageUp:
+		s.moveSelection(-s.pageSize)
+	case KeyPageDown:
+		s.moveSelection(s.pageSize)
+	case KeyHome, 'g':
+		s.moveSelection(-len(s.previous))
+	case KeyEnd, 'G':
+		s.moveSelection(len(s.previous))
+	case KeyEnter, KeyRight:
+		return s.openSelected(sess)
+	case 'K':
+		s.killSelected(sess)
+	case '/':
+		s.filtering = true
+	case KeyEscape:
+		s.filter = ""
+	case 's':
+		s.config.SortBy = nextSortOrder(s.config.SortBy)
+	case 'p':
+		s.paused = !s.paused
+		if !s.paused {
+			s.update()
+		}
+	}
+
+	return false
+}
+
+// editFilter handles keys while the filter prompt is open
+func (s *WatchState) editFilter(key rune) {
+	switch key {
+	case KeyEnter:
+		s.filtering = false
+	case KeyEscape:
+		s.filter = ""
+		s.filtering = false
+	case KeyBackspace:
+		if r := []rune(s.filter); len(r) > 0 {
+			s.filter = string(r[:len(r)-1])
+		}
+	default:
+		if key >= ' ' {
+			s.filter += string(key)
+		}
+	}
+}
+
+// nextSortOrder returns the sort key after the current one
+func nextSortOrder(current string) string {
+	for i, by := range sortOrders {
+		if by == strings.ToLower(current) {
+			return sortOrders[(i+1)%len(sortOrders)]
+		}
+	}
+	return sortOrders[0]
+}
+
+// visible returns the listeners shown in the table, filtered and sorted
+func (s *WatchState) visible() []model.Listener {
+	listeners := make([]model.Listener, 0, len(s.previous))
+	for _, l := range s.previous {
+		if matchesText(l, s.filter) {
+			listeners = append(listeners, l)
+		}
+	}
+	output.SortListeners(listeners, s.config.SortBy)
+	return listeners
+}
+
+// matchesText reports whether a listener matches a live filter. Like
+// `portman find`, it matches port, PID, process name, command, and user.
+func matchesText(l model.Listener, text string) bool {
+	if text == "" {
+		return true
+	}
+
+	if n, err := strconv.Atoi(text); err == nil && (l.Port == n || l.PID == n) {
+		return true
+	}
+
+	if l.Process == nil {
+		return false
+	}
+
+	text = strings.ToLower(text)
+	return strings.Contains(strings.ToLower(l.Process.Name), text) ||
+		strings.Contains(strings.ToLower(l.Process.Command), text) ||
+		strings.Contains(strings.ToLower(l.Process.User), text)
+}
+
+// resolveSelection keeps the selection on the same listener across
+// refreshes, falling back to the same row when that listener is gone. It
+// returns the selected index, or -1 when nothing is visible.
+func (s *WatchState) resolveSelection(listeners []model.Listener) int {
+	if len(listeners) == 0 {
+		return -1
+	}
+
+	for i, l := range listeners {
+		if keyOf(l) == s.selectedKey {
+			s.selected = i
+			return i
+		}
+	}
+
+	s.selected = max(0, min(s.selected, len(listeners)-1))
+	s.selectedKey = keyOf(listeners[s.selected])
+	return s.selected
+}
+
+// moveSelection moves the selected row by delta, clamped to the table
+func (s *WatchState) moveSelection(delta int) {
+	listeners := s.visible()
+	i := s.resolveSelection(listeners)
+	if i < 0 {
+		return
+	}
+
+	s.selected = max(0, min(i+delta, len(listeners)-1))
+	s.selectedKey = keyOf(listeners[s.selected])
+}
+
+// selectedListener returns the listener on the selected row
+func (s *WatchState) selectedListener() (model.Listener, bool) {
+	listeners := s.visible()
+	i := s.resolveSelection(listeners)
+	if i < 0 {
+		return model.Listener{}, false
+	}
+	return listeners[i], true
+}
+
+// openSelected shows the single-port view for the selected row until the
+// user goes back. It returns true if the session should end.
+func (s *WatchState) openSelected(sess *session) bool {
+	l, ok := s.selectedListener()
+	if !ok {
+		return false
+	}
+
+	quit := newPortWatchState(WatchPortConfig{
+		Scanner:  s.config.Scanner,
+		Port:     l.Port,
+		Interval: s.config.Interval,
+		History:  s.config.History,
+	}, true).run(sess)
+
+	s.isFirstRender = true
+	if !quit {
+		s.update()
+	}
+	return quit
+}
+
+// killSelected confirms and sends SIGTERM to the process on the selected
+// row, as `portman kill` does. The wait for it to exit runs in the
+// background, and its outcome arrives on s.kills.
+func (s *WatchState) killSelected(sess *session) {
+	l, ok := s.selectedListener()
+	if !ok || l.PID <= 0 {
+		return
+	}
+
+	ClearAndReset()
+	fmt.Print(KillSummary(l))
+	fmt.Println()
+	fmt.Print("Confirm [y/N]: ")
+
+	var answer rune
+	select {
+	case answer = <-sess.keys:
+	case sig := <-sess.signals:
+		// Leave the signal for the main loop to handle
+		sess.signals <- sig
+	}
+	fmt.Println()
+
+	s.isFirstRender = true
+	if answer != 'y' && answer != 'Y' {
+		s.message = "Aborted."
+		return
+	}
+
+	opts, _ := kill.NewOptions("TERM", false, kill.DefaultTimeout)
+	s.message = fmt.Sprintf("Port %d: sent SIGTERM to process %d...", l.Port, l.PID)
+	go func() {
+		err := kill.Terminate(l.PID, opts)
+		s.kills <- killMessage(l, err)
+	}()
+}
+
+// killMessage describes the outcome of killing a listener's process
+func killMessage(l model.Listener, err error) string {
+	switch {
+	case errors.Is(err, kill.ErrPermissionDenied):
+		return fmt.Sprintf("%sPort %d: permission denied. Try running with sudo.%s", Red, l.Port, Reset)
+	case errors.Is(err, kill.ErrProcessRunning):
+		return fmt.Sprintf("%sPort %d: process %d didn't terminate. Use `portman kill --force %d`.%s", Red, l.Port, l.PID, l.Port, Reset)
+	case err != nil:
+		return fmt.Sprintf("%sPort %d: %v%s", Red, l.Port, err, Reset)
+	default:
+		return fmt.Sprintf("%sPort %d: process %d terminated.%s", Green, l.Port, l.PID, Reset)
+	}
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+func TestKillSelectedWaitsInBackground(t *testing.T) {
+	// Ignores SIGTERM, so the kill waits out the whole timeout
+	cmd := exec.Command("sh", "-c", "trap '' TERM; while :; do sleep 0.05; done")
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	go cmd.Wait()
+	t.Cleanup(func() { cmd.Process.Kill() })
+	time.Sleep(100 * time.Millisecond) // Let sh install the trap
+
+	s := newWatchState(WatchConfig{})
+	l := listener(8080, "tcp", "127.0.0.1", cmd.Process.Pid, "sh", 0)
+	s.previous = listenerMap([]model.Listener{l})
+
+	sess := &session{keys: make(chan rune, 1)}
+	sess.keys <- 'y'
+	start := time.Now()
+	s.killSelected(sess)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("killSelected() blocked for %v", elapsed)
+	}
+	if !strings.Contains(s.message, "sent SIGTERM") {
+		t.Errorf("message = %q, want the signal to be reported as sent", s.message)
+	}
+
+	select {
+	case msg := <-s.kills:
+		if !strings.Contains(msg, "didn't terminate") {
+			t.Errorf("kill outcome = %q, want the process to still be running", msg)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("no kill outcome")
+	}
+}
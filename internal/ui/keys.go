@@ -0,0 +1,89 @@
+package ui
+
+import "unicode/utf8"
+
+// Special keys decoded from terminal escape sequences. They use negative
+// values so they can share a channel with ordinary runes.
+const (
+	KeyUp rune = -(iota + 1)
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEscape
+)
+
+const (
+	KeyEnter     rune = '\n'
+	KeyBackspace rune = 0x7f
+)
+
+// decodeKeys splits a chunk of terminal input into keys, translating
+// escape sequences for arrows and navigation keys
+func decodeKeys(b []byte) []rune {
+	var keys []rune
+
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			k, size := decodeEscape(b)
+			keys = append(keys, k)
+			b = b[size:]
+			continue
+		}
+
+		r, size := utf8.DecodeRune(b)
+		switch r {
+		case '\r':
+			r = KeyEnter
+		case 0x08:
+			r = KeyBackspace
+		}
+		keys = append(keys, r)
+		b = b[size:]
+	}
+
+	return keys
+}
+
+// decodeEscape decodes an escape sequence at the start of b, returning the
+// key and the number of bytes consumed. A lone ESC is the escape key.
+func decodeEscape(b []byte) (rune, int) {
+	if len(b) < 3 || (b[1] != '[' && b[1] != 'O') {
+		return KeyEscape, 1
+	}
+
+	switch b[2] {
+	case 'A':
+		return KeyUp, 3
+	case 'B':
+		return KeyDown, 3
+	case 'C':
+		return KeyRight, 3
+	case 'D':
+		return KeyLeft, 3
+	case 'H':
+		return KeyHome, 3
+	case 'F':
+		return KeyEnd, 3
+	}
+
+	// Sequences of the form ESC [ <n> ~
+	if len(b) >= 4 && b[3] == '~' {
+		switch b[2] {
+		case '1', '7':
+			return KeyHome, 4
+		case '4', '8':
+			return KeyEnd, 4
+		case '5':
+			return KeyPageUp, 4
+		case '6':
+			return KeyPageDown, 4
+		}
+		return KeyEscape, 4
+	}
+
+	return KeyEscape, 1
+}
@@ -2,120 +2,267 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/tasnimzotder/portman/internal/model"
 	"github.com/tasnimzotder/portman/internal/output"
 )
 
-// render displays the current state with highlighting (flicker-free)
+// render displays the current state with highlighting, sized to the terminal
 func (s *WatchState) render() {
-	// Only clear screen on first render, then just move cursor to top
-	if s.isFirstRender {
-		ClearAndReset()
-		s.isFirstRender = false
-	} else {
-		MoveToTop()
-	}
+	f := newFrame()
 
 	// Header
-	PrintLine("%s%sportman --watch%s  ", Bold, Cyan, Reset)
-	fmt.Printf("%sRefresh: %s%s  ", Dim, s.config.Interval, Reset)
-	fmt.Printf("%sPress 'q' to quit%s\n", Dim, Reset)
-	PrintLine("%s\n", strings.Repeat("─", 70))
-
-	// Column headers
-	PrintLine("%s%-8s %-8s %-8s %-10s %-8s %-12s %s%s\n",
-		Bold,
-		"PORT", "PROTO", "PID", "USER", "CONNS", "UPTIME", "PROCESS",
-		Reset)
-
-	if len(s.previous) == 0 {
-		PrintLine("\n")
-		PrintLine("%sNo listening ports found.%s\n", Dim, Reset)
-		// Clear remaining lines
-		for range 20 {
-			PrintLine("\n")
-		}
-		return
+	title := "portman --watch"
+	if s.config.Pattern != "" {
+		title = fmt.Sprintf("portman find %s --watch", s.config.Pattern)
+	}
+	header := fmt.Sprintf("%s%s%s%s  %sRefresh: %s  Sort: %s%s",
+		Bold, Cyan, title, Reset, Dim, s.config.Interval, s.config.SortBy, Reset)
+	if s.config.Filter != nil {
+		header += fmt.Sprintf("  %s--filter %s%s", Dim, s.config.Filter, Reset)
 	}
+	if s.filtering {
+		header += fmt.Sprintf("  Filter: /%s▏", s.filter)
+	} else if s.filter != "" {
+		header += fmt.Sprintf("  %sFilter: %s%s", Cyan, s.filter, Reset)
+	}
+	if s.paused {
+		header += fmt.Sprintf("  %s%sPAUSED%s", Bold, Yellow, Reset)
+	}
+	f.add("%s", header)
+	f.add("%s↑/↓ select  enter details  K kill  / filter  s sort  p pause  q quit%s", Dim, Reset)
+	f.rule()
 
-	// Convert map to slice for ordered display
-	listeners := make([]model.Listener, 0, len(s.previous))
-	for _, l := range s.previous {
-		listeners = append(listeners, l)
+	// Filter and sort for display
+	listeners := s.visible()
+	selected := s.resolveSelection(listeners)
+
+	headers := output.Headers(s.config.Columns)
+	rows := make([][]string, len(listeners))
+	for i, l := range listeners {
+		rows[i] = s.rowCells(l)
 	}
-	output.SortListeners(listeners, s.config.SortBy)
+	widths := columnWidths(headers, rows)
+	widths = fitColumns(widths, minWidths(headers), f.width)
+
+	f.add("%s%s%s", Bold, formatCells(headers, widths), Reset)
 
-	// Render each row
-	for _, l := range listeners {
-		s.renderRow(l)
+	// Everything below the table is built first so the table gets whatever
+	// height is left
+	bottom := &frame{width: f.width, height: f.height}
+	s.renderNotices(bottom, listeners)
+
+	if len(s.previous) == 0 && s.config.Filter != nil {
+		f.blank()
+		f.add("%sNo ports match the filter. Waiting...%s", Dim, Reset)
+	} else if len(s.previous) == 0 && s.config.Pattern != "" {
+		f.blank()
+		f.add("%sNo ports found matching '%s'. Waiting...%s", Dim, s.config.Pattern, Reset)
+	} else if len(s.previous) == 0 {
+		f.blank()
+		f.add("%sNo listening ports found.%s", Dim, Reset)
+	} else if len(listeners) == 0 {
+		f.blank()
+		f.add("%sNo ports match '%s'.%s", Dim, s.filter, Reset)
 	}
 
-	// Show removed ports briefly
-	if len(s.removed) > 0 {
-		PrintLine("\n")
-		for port := range s.removed {
-			PrintLine("%s  ● Port %d removed%s\n", Red, port, Reset)
-		}
+	// Scrollable viewport over the rows, kept around the selection
+	s.pageSize = max(1, f.height-len(f.lines)-len(bottom.lines)-2)
+	first, last := s.scrollTo(selected, len(listeners))
+	for i := first; i < last; i++ {
+		s.renderRow(f, listeners[i], formatCells(rows[i], widths), i == selected)
 	}
 
 	// Footer
-	PrintLine("\n")
-	PrintLine("%s%d ports%s", Dim, len(listeners), Reset)
+	f.blank()
+	var footer string
+	switch {
+	case last-first < len(listeners):
+		footer = fmt.Sprintf("%sshowing %d-%d of %d ports%s", Dim, first+1, last, len(listeners), Reset)
+	case s.filter != "":
+		footer = fmt.Sprintf("%s%d of %d ports%s", Dim, len(listeners), len(s.previous), Reset)
+	default:
+		footer = fmt.Sprintf("%s%d ports%s", Dim, len(listeners), Reset)
+	}
+	if s.filter != "" && last-first < len(listeners) {
+		footer += fmt.Sprintf("%s (%d total)%s", Dim, len(s.previous), Reset)
+	}
 	if len(s.added) > 0 {
-		fmt.Printf("  %s+%d new%s", Green, len(s.added), Reset)
+		footer += fmt.Sprintf("  %s+%d new%s", Green, len(s.added), Reset)
 	}
 	if len(s.removed) > 0 {
-		fmt.Printf("  %s-%d removed%s", Red, len(s.removed), Reset)
+		footer += fmt.Sprintf("  %s-%d removed%s", Red, len(s.removed), Reset)
 	}
-	fmt.Println()
-
-	// Clear any leftover lines from previous renders
-	for range 10 {
-		PrintLine("\n")
+	if len(s.restarted) > 0 {
+		footer += fmt.Sprintf("  %s↻%d restarted%s", Yellow, len(s.restarted), Reset)
+	}
+	if s.scanErr != nil {
+		msg, _, _ := strings.Cut(s.scanErr.Error(), "\n")
+		footer += fmt.Sprintf("  %sScan failed: %s%s", Red, msg, Reset)
 	}
+	f.add("%s", footer)
+
+	f.lines = append(f.lines, bottom.lines...)
+	f.draw(s.isFirstRender)
+	s.isFirstRender = false
 }
 
-// renderRow renders a single listener row with appropriate highlighting
-func (s *WatchState) renderRow(l model.Listener) {
-	processName := ""
-	user := ""
-	uptime := ""
-
-	if l.Process != nil {
-		processName = l.Process.Name
-		if len(processName) > 20 {
-			processName = processName[:17] + "..."
+// renderNotices adds the removed, restarted, and flapping notices, the
+// status message, and the event log
+func (s *WatchState) renderNotices(f *frame, listeners []model.Listener) {
+	// Show removed listeners briefly
+	if len(s.removed) > 0 {
+		keys := make([]listenerKey, 0, len(s.removed))
+		for key := range s.removed {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+
+		f.blank()
+		for _, key := range keys {
+			f.add("%s  ● Port %d/%s removed%s", Red, key.Port, key.Protocol, Reset)
 		}
-		user = l.Process.User
-		if len(user) > 10 {
-			user = user[:7] + "..."
+	}
+
+	// Show restarts briefly, and flapping warnings while they last
+	if len(s.restarted) > 0 {
+		f.blank()
+		for _, l := range listeners {
+			if r, ok := s.restarted[keyOf(l)]; ok {
+				f.add("%s  ● Port %d %s%s", Yellow, l.Port, describeRestart(r, l), Reset)
+			}
 		}
-		if l.Process.UptimeSeconds > 0 {
-			uptime = output.FormatDuration(l.Process.UptimeSeconds)
+	}
+	now := time.Now()
+	if flapping := s.restarts.flappingPorts(now); len(flapping) > 0 {
+		f.blank()
+		for _, port := range flapping {
+			n, span, _ := s.restarts.flapping(port, now)
+			f.add("%s%s  ⚠ Port %d %s%s", Bold, Yellow, port, formatFlapping(n, span), Reset)
 		}
 	}
 
+	if s.message != "" {
+		f.blank()
+		f.add("%s", s.message)
+	}
+
+	s.log.render(f)
+}
+
+// scrollTo adjusts the scroll offset so the selected row is visible and
+// returns the range of rows to draw
+func (s *WatchState) scrollTo(selected, total int) (int, int) {
+	if total <= s.pageSize {
+		s.offset = 0
+		return 0, total
+	}
+
+	if selected >= 0 {
+		if selected < s.offset {
+			s.offset = selected
+		} else if selected >= s.offset+s.pageSize {
+			s.offset = selected - s.pageSize + 1
+		}
+	}
+	s.offset = max(0, min(s.offset, total-s.pageSize))
+
+	return s.offset, s.offset + s.pageSize
+}
+
+// rowCells returns the table cells for a listener, marking restarts on the
+// process name column, or the last column when it isn't shown
+func (s *WatchState) rowCells(l model.Listener) []string {
+	cells := output.Cells(s.config.Columns, l)
+	if len(cells) == 0 {
+		return cells
+	}
+
+	if n := s.restarts.count(l.Port); n > 0 {
+		mark := len(cells) - 1
+		for i, c := range s.config.Columns {
+			if c.Name == "name" {
+				mark = i
+			}
+		}
+		cells[mark] = fmt.Sprintf("%s ↻%d", cells[mark], n)
+	}
+
+	return cells
+}
+
+// renderRow renders a single listener row with appropriate highlighting
+func (s *WatchState) renderRow(f *frame, l model.Listener, row string, selected bool) {
 	// Choose color based on state
 	color := ""
-	if s.added[l.Port] {
+	if s.added[keyOf(l)] {
 		color = Green
+	} else if _, ok := s.restarted[keyOf(l)]; ok {
+		color = Yellow
 	}
 
-	row := fmt.Sprintf("%-8d %-8s %-8d %-10s %-8d %-12s %s",
-		l.Port,
-		l.Protocol,
-		l.PID,
-		user,
-		l.ConnectionCount,
-		uptime,
-		processName,
-	)
+	if selected {
+		color += Reverse
+		// Extend the highlight across the full width
+		row += strings.Repeat(" ", max(0, f.width-visibleWidth(row)))
+	}
 
 	if color != "" {
-		PrintLine("%s%s%s\n", color, row, Reset)
+		f.add("%s%s%s", color, row, Reset)
 	} else {
-		PrintLine("%s\n", row)
+		f.add("%s", row)
+	}
+}
+
+// columnWidths returns the natural width of each column: the widest of its
+// header and cells
+func columnWidths(headers []string, rows [][]string) []int {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = visibleWidth(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], visibleWidth(cell))
+		}
+	}
+	return widths
+}
+
+// minWidths returns the narrowest each column may shrink to: its header
+func minWidths(headers []string) []int {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = visibleWidth(h)
+	}
+	return widths
+}
+
+// formatCells pads and clips cells to the given widths
+func formatCells(cells []string, widths []int) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = clip(cell, widths[i])
+		if i == len(cells)-1 {
+			parts[i] = cell
+		} else {
+			parts[i] = fmt.Sprintf("%-*s", widths[i], cell)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeRestart explains an owner change or restart for a port
+func describeRestart(r restart, cur model.Listener) string {
+	if r.kind == EventOwnerChanged {
+		return fmt.Sprintf("owner changed: %s (pid %d) → %s (pid %d)",
+			processName(r.previous), r.previous.PID, processName(cur), cur.PID)
+	}
+	if r.previous.PID != cur.PID {
+		return fmt.Sprintf("restarted (pid %d → %d)", r.previous.PID, cur.PID)
 	}
+	return fmt.Sprintf("restarted (pid %d)", cur.PID)
 }
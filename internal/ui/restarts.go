@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+const (
+	// flapThreshold is the number of restarts within flapWindow that
+	// triggers a flapping warning
+	flapThreshold = 3
+	flapWindow    = 5 * time.Minute
+)
+
+// ownerChange classifies how the process behind a port changed between two
+// observations. It returns EventOwnerChanged when a different program took
+// over the port, EventRestarted when the same program came back with a new
+// PID or start time, and "" when nothing changed.
+func ownerChange(prev, cur model.Listener) string {
+	if prev.PID != cur.PID {
+		if processName(prev) != "" && processName(prev) != processName(cur) {
+			return EventOwnerChanged
+		}
+		return EventRestarted
+	}
+
+	if prev.Process == nil || cur.Process == nil {
+		return ""
+	}
+
+	if !prev.Process.StartTime.IsZero() && !cur.Process.StartTime.IsZero() {
+		if !prev.Process.StartTime.Equal(cur.Process.StartTime) {
+			return EventRestarted
+		}
+		return ""
+	}
+
+	// Uptime going backwards means the PID was reused by a new process.
+	// Allow a second of jitter from the elapsed time resolution.
+	if cur.Process.UptimeSeconds+1 < prev.Process.UptimeSeconds {
+		return EventRestarted
+	}
+
+	return ""
+}
+
+// restartTracker counts owner changes and in-place restarts per port for the
+// lifetime of a watch session. Each listener on a port is compared with its
+// own last observation.
+type restartTracker struct {
+	lastSeen map[listenerKey]model.Listener
+	restarts map[int][]time.Time
+}
+
+func newRestartTracker() *restartTracker {
+	return &restartTracker{
+		lastSeen: make(map[listenerKey]model.Listener),
+		restarts: make(map[int][]time.Time),
+	}
+}
+
+// observe records the listener and returns the kind of change compared to
+// the last time it was seen, along with that earlier listener. Listeners
+// that vanish and come back with a different process count as restarts too.
+func (t *restartTracker) observe(l model.Listener, now time.Time) (string, model.Listener) {
+	key := keyOf(l)
+	prev, seen := t.lastSeen[key]
+	t.lastSeen[key] = l
+	if !seen {
+		return "", prev
+	}
+
+	change := ownerChange(prev, l)
+	if change != "" {
+		t.restarts[l.Port] = append(t.restarts[l.Port], now)
+	}
+	return change, prev
+}
+
+// seen reports whether anything has listened on a port this session
+func (t *restartTracker) seen(port int) bool {
+	for key := range t.lastSeen {
+		if key.Port == port {
+			return true
+		}
+	}
+	return false
+}
+
+// count returns the number of restarts seen on a port this session
+func (t *restartTracker) count(port int) int {
+	return len(t.restarts[port])
+}
+
+// flapping reports how many times a port restarted within flapWindow and the
+// span those restarts cover, if that meets flapThreshold.
+func (t *restartTracker) flapping(port int, now time.Time) (int, time.Duration, bool) {
+	var recent []time.Time
+	for _, ts := range t.restarts[port] {
+		if now.Sub(ts) <= flapWindow {
+			recent = append(recent, ts)
+		}
+	}
+
+	if len(recent) < flapThreshold {
+		return 0, 0, false
+	}
+
+	return len(recent), now.Sub(recent[0]), true
+}
+
+// flappingPorts returns the ports currently flapping, in ascending order
+func (t *restartTracker) flappingPorts(now time.Time) []int {
+	var ports []int
+	for port := range t.restarts {
+		if _, _, ok := t.flapping(port, now); ok {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// formatFlapping renders a flapping warning such as "restarted 3x in 2m"
+func formatFlapping(n int, span time.Duration) string {
+	return fmt.Sprintf("restarted %dx in %s", n, shortDuration(span))
+}
+
+// shortDuration formats a duration with a single unit, e.g. "45s" or "2m"
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+}
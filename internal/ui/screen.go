@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Fallback size when the terminal size can't be read
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// terminalSize returns the width and height of the terminal on stdout
+func terminalSize() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return defaultWidth, defaultHeight
+	}
+	return width, height
+}
+
+// frame collects the lines of one screen update so they can be fitted to
+// the terminal before drawing
+type frame struct {
+	lines  []string
+	width  int
+	height int
+}
+
+// newFrame creates a frame sized to the current terminal
+func newFrame() *frame {
+	width, height := terminalSize()
+	return &frame{width: width, height: height}
+}
+
+// add appends a formatted line
+func (f *frame) add(format string, args ...any) {
+	f.lines = append(f.lines, fmt.Sprintf(format, args...))
+}
+
+// blank appends an empty line
+func (f *frame) blank() {
+	f.lines = append(f.lines, "")
+}
+
+// rule appends a horizontal rule spanning the terminal
+func (f *frame) rule() {
+	f.lines = append(f.lines, strings.Repeat("─", f.width))
+}
+
+// draw writes the frame to the terminal in a single write, clipping lines to
+// the terminal width and height. The first draw clears the screen; later
+// draws overwrite in place and clear whatever is left below (flicker-free).
+func (f *frame) draw(clear bool) {
+	var sb strings.Builder
+
+	if clear {
+		sb.WriteString(ClearScreen + MoveCursor)
+	} else {
+		sb.WriteString(MoveCursor)
+	}
+
+	lines := f.lines
+	if len(lines) > f.height {
+		lines = lines[:f.height]
+	}
+
+	for i, line := range lines {
+		sb.WriteString(ClearLine)
+		sb.WriteString(fitLine(line, f.width))
+		if i < len(lines)-1 {
+			sb.WriteString("\r\n")
+		}
+	}
+	sb.WriteString(ClearToEnd)
+
+	os.Stdout.WriteString(sb.String())
+}
+
+// fitLine cuts a line to at most width visible characters. ANSI escape
+// sequences are copied through without counting toward the width.
+func fitLine(s string, width int) string {
+	if visibleWidth(s) <= width {
+		return s
+	}
+
+	var sb strings.Builder
+	visible := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			end := escapeEnd(s, i)
+			sb.WriteString(s[i:end])
+			i = end
+			continue
+		}
+
+		if visible == width {
+			break
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		sb.WriteRune(r)
+		visible++
+		i += size
+	}
+
+	sb.WriteString(Reset)
+	return sb.String()
+}
+
+// visibleWidth counts the characters of s that occupy screen columns
+func visibleWidth(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			i = escapeEnd(s, i)
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		n++
+		i += size
+	}
+	return n
+}
+
+// escapeEnd returns the index just past the CSI escape sequence at s[i]
+func escapeEnd(s string, i int) int {
+	j := i + 1
+	if j < len(s) && s[j] == '[' {
+		j++
+		for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+			j++
+		}
+	}
+	return min(j+1, len(s))
+}
+
+// clip shortens s to at most n characters, marking the cut with "..."
+func clip(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	r := []rune(s)
+	if n <= 3 {
+		return string(r[:max(n, 0)])
+	}
+	return string(r[:n-3]) + "..."
+}
+
+// fitColumns shrinks column widths until the row fits in width, taking from
+// the widest column that is still above its minimum. Columns are separated
+// by a single space.
+func fitColumns(widths, mins []int, width int) []int {
+	fitted := append([]int(nil), widths...)
+
+	total := len(fitted) - 1
+	for _, w := range fitted {
+		total += w
+	}
+
+	for total > width {
+		widest := -1
+		for i, w := range fitted {
+			if w > mins[i] && (widest < 0 || w > fitted[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		fitted[widest]--
+		total--
+	}
+
+	return fitted
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// keyPollInterval bounds how long the key reader takes to notice it should stop
+const keyPollInterval = 100 * time.Millisecond
+
+// terminal owns the controlling terminal for the duration of a watch session
+type terminal struct {
+	tty     *os.File
+	fd      int
+	saved   *unix.Termios
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// openTerminal opens /dev/tty and switches it to cbreak mode: input is
+// delivered a key at a time without echo, while Ctrl-C still raises SIGINT
+// and output processing is left alone.
+func openTerminal() (*terminal, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	fd := int(tty.Fd())
+
+	saved, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		tty.Close()
+		return nil, err
+	}
+
+	raw := *saved
+	raw.Lflag &^= unix.ICANON | unix.ECHO
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		tty.Close()
+		return nil, err
+	}
+
+	return &terminal{
+		tty:     tty,
+		fd:      fd,
+		saved:   saved,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}, nil
+}
+
+// readKeys sends decoded keys until the terminal is closed or reading fails.
+// It polls with a timeout so it never stays blocked in read after close.
+func (t *terminal) readKeys(keys chan<- rune) {
+	defer close(t.stopped)
+
+	buf := make([]byte, 32)
+	fds := []unix.PollFd{{Fd: int32(t.fd), Events: unix.POLLIN}}
+
+	for {
+		select {
+		case <-t.done:
+			return
+		default:
+		}
+
+		n, err := unix.Poll(fds, int(keyPollInterval/time.Millisecond))
+		if errors.Is(err, unix.EINTR) || n == 0 {
+			continue
+		}
+		if err != nil || fds[0].Revents&(unix.POLLERR|unix.POLLHUP|unix.POLLNVAL) != 0 {
+			return
+		}
+
+		n, err = unix.Read(t.fd, buf)
+		if errors.Is(err, unix.EINTR) || errors.Is(err, unix.EAGAIN) {
+			continue
+		}
+		if err != nil || n == 0 {
+			return
+		}
+
+		for _, k := range decodeKeys(buf[:n]) {
+			select {
+			case keys <- k:
+			case <-t.done:
+				return
+			}
+		}
+	}
+}
+
+// close stops the key reader and restores the saved terminal state
+func (t *terminal) close() {
+	close(t.done)
+	select {
+	case <-t.stopped:
+	case <-time.After(2 * keyPollInterval):
+	}
+
+	unix.IoctlSetTermios(t.fd, ioctlSetTermios, t.saved)
+	t.tty.Close()
+}
+
+// IsTerminal reports whether f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -3,12 +3,11 @@ package ui
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
+	"github.com/tasnimzotder/portman/internal/filter"
 	"github.com/tasnimzotder/portman/internal/model"
 	"github.com/tasnimzotder/portman/internal/output"
 	"github.com/tasnimzotder/portman/internal/scanner"
@@ -18,9 +17,15 @@ import (
 type WatchConfig struct {
 	Scanner  scanner.Scanner
 	Interval time.Duration
-	SortBy   string
+	SortBy   string // Sort order such as "user,-conns" (see output.ParseSort)
 	TCPOnly  bool
 	UDPOnly  bool
+	Pattern  string          // Only show listeners matching this find pattern
+	LogLines int             // Number of events shown in the log panel (0 hides it)
+	LogFile  string          // Optional file the event log is appended to
+	History  time.Duration   // Sparkline window for the detail view
+	Columns  []output.Column // Table columns (default: output.DefaultWatchColumns)
+	Filter   *filter.Expr    // Only show listeners matching this --filter expression
 }
 
 // WatchPortConfig holds configuration for single-port watch mode
@@ -28,6 +33,7 @@ type WatchPortConfig struct {
 	Scanner  scanner.Scanner
 	Port     int
 	Interval time.Duration
+	History  time.Duration // Sparkline window (0 keeps the whole session)
 }
 
 // PortSnapshot tracks previous values for change detection
@@ -40,251 +46,66 @@ type PortSnapshot struct {
 	ThreadCount     int
 }
 
-// RunWatchPort starts watch mode for a single port
-func RunWatchPort(cfg WatchPortConfig) error {
-	cleanup := setupTerminal()
-	defer cleanup()
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	keyChan := make(chan rune, 1)
-	go func() {
-		tty, err := os.Open("/dev/tty")
-		if err != nil {
-			return
-		}
-		defer tty.Close()
-		buf := make([]byte, 1)
-		for {
-			n, err := tty.Read(buf)
-			if err != nil || n == 0 {
-				continue
-			}
-			keyChan <- rune(buf[0])
-		}
-	}()
-
-	ticker := time.NewTicker(cfg.Interval)
-	defer ticker.Stop()
-
-	var prevListener *model.Listener
-	var prevSnapshot *PortSnapshot
-	isFirstRender := true
-
-	renderPort := func() {
-		// Only clear screen on first render, then just move cursor to top
-		if isFirstRender {
-			ClearAndReset()
-			isFirstRender = false
-		} else {
-			MoveToTop()
-		}
-
-		// Header
-		PrintLine("%s%sportman port %d%s  ", Bold, Cyan, cfg.Port, Reset)
-		fmt.Printf("%sRefresh: %s%s  ", Dim, cfg.Interval, Reset)
-		fmt.Printf("%sPress 'q' to quit%s\n", Dim, Reset)
-		PrintLine("%s\n", strings.Repeat("─", 70))
-
-		listener, _ := cfg.Scanner.GetPort(cfg.Port)
-
-		if listener == nil {
-			PrintLine("\n")
-			PrintLine("%sPort %d is not in use.%s\n", Dim, cfg.Port, Reset)
-			if prevListener != nil {
-				PrintLine("%s  ● Process exited%s\n", Red, Reset)
-			} else {
-				PrintLine("\n")
-			}
-			// Clear all remaining lines (must cover full output: ~25 lines)
-			for range 22 {
-				PrintLine("\n")
-			}
-			prevListener = nil
-			prevSnapshot = nil
-			return
-		}
-
-		// Show if newly appeared
-		if prevListener == nil {
-			PrintLine("%s  ● Port became active%s\n", Green, Reset)
-			PrintLine("\n")
-		} else {
-			PrintLine("\n")
-			PrintLine("\n")
-		}
-
-		// Process info
-		PrintLine("%sProcess%s\n", Bold, Reset)
-		PrintLine("  PID:         %d\n", listener.PID)
-		if listener.Process != nil {
-			PrintLine("  Command:     %s\n", listener.Process.Name)
-			PrintLine("  User:        %s\n", listener.Process.User)
-			if listener.Process.UptimeSeconds > 0 {
-				PrintLine("  Uptime:      %s\n", time.Duration(listener.Process.UptimeSeconds)*time.Second)
-			} else {
-				PrintLine("\n")
-			}
-		} else {
-			PrintLine("\n")
-			PrintLine("\n")
-			PrintLine("\n")
-		}
-
-		PrintLine("\n")
-		PrintLine("%sListening%s\n", Bold, Reset)
-		PrintLine("  Address:     %s:%d\n", listener.Address, listener.Port)
-		PrintLine("  Protocol:    %s\n", strings.ToUpper(listener.Protocol))
-
-		// Connections with change highlighting
-		connChanged := prevSnapshot != nil && listener.ConnectionCount != prevSnapshot.ConnectionCount
-		PrintLine("\n")
-		if connChanged {
-			delta := listener.ConnectionCount - prevSnapshot.ConnectionCount
-			sign := "+"
-			if delta < 0 {
-				sign = ""
-			}
-			PrintLine("%sConnections%s %s%d%s (%s%d)%s\n", Bold, Reset, Yellow, listener.ConnectionCount, Reset, sign, delta, Reset)
-		} else {
-			PrintLine("%sConnections%s (%d)\n", Bold, Reset, listener.ConnectionCount)
-		}
-
-		// Show connections (up to 5)
-		if len(listener.Connections) > 0 {
-			connCount := min(len(listener.Connections), 5)
-			for i := range connCount {
-				c := listener.Connections[i]
-				PrintLine("  %s:%d  %s\n", c.RemoteAddr, c.RemotePort, c.State)
-			}
-			if len(listener.Connections) > 5 {
-				PrintLine("  %s... and %d more%s\n", Dim, len(listener.Connections)-5, Reset)
-			}
-		}
-
-		// Stats with change highlighting
-		PrintLine("\n")
-		PrintLine("%sStats%s\n", Bold, Reset)
-		if listener.Stats != nil {
-			// Memory
-			memChanged := prevSnapshot != nil && listener.Stats.MemoryRSS != prevSnapshot.MemoryRSS
-			if memChanged {
-				PrintLine("  Memory:      %s%s%s\n", Yellow, output.FormatBytes(listener.Stats.MemoryRSS), Reset)
-			} else {
-				PrintLine("  Memory:      %s\n", output.FormatBytes(listener.Stats.MemoryRSS))
-			}
-
-			// CPU
-			cpuChanged := prevSnapshot != nil && listener.Stats.CPUPercent != prevSnapshot.CPUPercent
-			if cpuChanged {
-				PrintLine("  CPU:         %s%.1f%%%s\n", Yellow, listener.Stats.CPUPercent, Reset)
-			} else {
-				PrintLine("  CPU:         %.1f%%\n", listener.Stats.CPUPercent)
-			}
-
-			// FDs
-			fdChanged := prevSnapshot != nil && listener.Stats.FDCount != prevSnapshot.FDCount
-			if fdChanged {
-				PrintLine("  FDs:         %s%d%s\n", Yellow, listener.Stats.FDCount, Reset)
-			} else {
-				PrintLine("  FDs:         %d\n", listener.Stats.FDCount)
-			}
-
-			// Threads
-			threadChanged := prevSnapshot != nil && listener.Stats.ThreadCount != prevSnapshot.ThreadCount
-			if threadChanged {
-				PrintLine("  Threads:     %s%d%s\n", Yellow, listener.Stats.ThreadCount, Reset)
-			} else {
-				PrintLine("  Threads:     %d\n", listener.Stats.ThreadCount)
-			}
-
-			// Update snapshot
-			prevSnapshot = &PortSnapshot{
-				PID:             listener.PID,
-				ConnectionCount: listener.ConnectionCount,
-				MemoryRSS:       listener.Stats.MemoryRSS,
-				CPUPercent:      listener.Stats.CPUPercent,
-				FDCount:         listener.Stats.FDCount,
-				ThreadCount:     listener.Stats.ThreadCount,
-			}
-		} else {
-			PrintLine("  %sNo stats available%s\n", Dim, Reset)
-			prevSnapshot = &PortSnapshot{
-				PID:             listener.PID,
-				ConnectionCount: listener.ConnectionCount,
-			}
-		}
-
-		// Clear any leftover lines from previous renders (e.g., when connections decrease)
-		for range 6 {
-			PrintLine("\n")
-		}
-
-		prevListener = listener
-	}
-
-	renderPort()
-
-	for {
-		select {
-		case <-sigChan:
-			return nil
-		case key := <-keyChan:
-			if key == 'q' || key == 'Q' {
-				return nil
-			}
-		case <-ticker.C:
-			renderPort()
-		}
-	}
-}
-
 // WatchState tracks the current state of watch mode
 type WatchState struct {
 	config        WatchConfig
-	previous      map[int]model.Listener
-	added         map[int]bool
-	removed       map[int]bool
+	previous      map[listenerKey]model.Listener
+	added         map[listenerKey]bool
+	removed       map[listenerKey]bool
+	restarted     map[listenerKey]restart
+	restarts      *restartTracker
+	log           *eventLog
+	scanned       bool
+	scanErr       error // Error of the last scan, shown until one succeeds
 	isFirstRender bool
+
+	// Interactive state
+	selected    int         // Index of the selected row
+	offset      int         // First row shown in the viewport
+	pageSize    int         // Rows that fit in the viewport
+	selectedKey listenerKey // Listener on the selected row, kept across refreshes
+	filter      string      // Live text filter
+	filtering   bool        // Filter prompt is open
+	paused      bool        // Refreshing is paused
+	message     string      // Status message shown until the next key
+	kills       chan string // Outcomes of kills waiting in the background
 }
 
-// RunWatch starts watch mode with live updates
-func RunWatch(cfg WatchConfig) error {
-	state := &WatchState{
+// restart records an owner change or in-place restart seen this tick
+type restart struct {
+	kind     string
+	previous model.Listener
+}
+
+func newWatchState(cfg WatchConfig) *WatchState {
+	if len(cfg.Columns) == 0 {
+		cfg.Columns, _ = output.LookupColumns(output.DefaultWatchColumns)
+	}
+	return &WatchState{
 		config:        cfg,
-		previous:      make(map[int]model.Listener),
-		added:         make(map[int]bool),
-		removed:       make(map[int]bool),
+		previous:      make(map[listenerKey]model.Listener),
+		added:         make(map[listenerKey]bool),
+		removed:       make(map[listenerKey]bool),
+		restarted:     make(map[listenerKey]restart),
+		restarts:      newRestartTracker(),
 		isFirstRender: true,
+		kills:         make(chan string, 1),
 	}
+}
 
-	// Set up terminal
-	cleanup := setupTerminal()
-	defer cleanup()
-
-	// Handle signals for clean exit
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+// RunWatch starts watch mode with live updates
+func RunWatch(cfg WatchConfig) error {
+	state := newWatchState(cfg)
 
-	// Key input channel - read from /dev/tty for raw input
-	keyChan := make(chan rune, 1)
-	go func() {
-		tty, err := os.Open("/dev/tty")
-		if err != nil {
-			return
-		}
-		defer tty.Close()
+	events, err := newEventLog(cfg.LogLines, cfg.LogFile)
+	if err != nil {
+		return err
+	}
+	defer events.Close()
+	state.log = events
 
-		buf := make([]byte, 1)
-		for {
-			n, err := tty.Read(buf)
-			if err != nil || n == 0 {
-				continue
-			}
-			keyChan <- rune(buf[0])
-		}
-	}()
+	sess := startSession()
+	defer sess.close()
 
 	ticker := time.NewTicker(cfg.Interval)
 	defer ticker.Stop()
@@ -297,53 +118,89 @@ func RunWatch(cfg WatchConfig) error {
 
 	for {
 		select {
-		case <-sigChan:
+		case <-sess.signals:
 			return nil
-		case key := <-keyChan:
-			if key == 'q' || key == 'Q' {
+		case <-sess.resize:
+			state.render()
+		case key := <-sess.keys:
+			if state.handleKey(sess, key) {
 				return nil
 			}
+			state.render()
+		case msg := <-state.kills:
+			state.message = msg
+			state.update()
+			state.render()
 		case <-ticker.C:
-			if err := state.update(); err != nil {
-				continue // Keep trying on errors
+			if state.paused {
+				continue
 			}
+			// A failed scan keeps the last listeners on screen, with the
+			// error in the status line
+			state.update()
 			state.render()
 		}
 	}
 }
 
-// setupTerminal prepares the terminal for watch mode
-func setupTerminal() func() {
-	// Put terminal in raw mode for single-key input (macOS compatible)
-	// Use -f /dev/tty for macOS
-	tty, _ := os.Open("/dev/tty")
-	if tty != nil {
-		cmd := exec.Command("stty", "-f", "/dev/tty", "cbreak", "-echo")
-		cmd.Stdin = tty
-		cmd.Run()
-		tty.Close()
+// session holds the terminal state and input shared by the watch views
+type session struct {
+	keys    chan rune
+	signals chan os.Signal
+	resize  chan os.Signal
+	tty     *terminal // nil when there is no controlling terminal
+}
+
+// startSession prepares the terminal and starts reading keys and signals
+func startSession() *session {
+	sess := &session{
+		keys:    make(chan rune, 1),
+		signals: make(chan os.Signal, 1),
+		resize:  make(chan os.Signal, 1),
+	}
+
+	// Handle signals for clean exit, and redraw when the window resizes
+	signal.Notify(sess.signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	signal.Notify(sess.resize, syscall.SIGWINCH)
+
+	// Single-key input from the controlling terminal. Without one, watch
+	// mode still runs and exits on Ctrl-C.
+	if tty, err := openTerminal(); err == nil {
+		sess.tty = tty
+		go tty.readKeys(sess.keys)
 	}
 
+	// Draw on the alternate screen so the scrollback stays clean
+	fmt.Print(AltScreen)
 	Hide()
 	ClearAndReset()
 
-	return func() {
-		Show()
-		// Restore terminal
-		tty, _ := os.Open("/dev/tty")
-		if tty != nil {
-			cmd := exec.Command("stty", "-f", "/dev/tty", "sane")
-			cmd.Stdin = tty
-			cmd.Run()
-			tty.Close()
-		}
-		fmt.Println()
+	return sess
+}
+
+// close stops input and signal handling and restores the terminal. It is
+// deferred by the watch views so it also runs when they panic.
+func (sess *session) close() {
+	Show()
+	fmt.Print(MainScreen)
+
+	if sess.tty != nil {
+		sess.tty.close()
 	}
+	signal.Stop(sess.signals)
+	signal.Stop(sess.resize)
 }
 
 // update fetches new data and computes diff
 func (s *WatchState) update() error {
-	listeners, err := s.config.Scanner.ListListeners()
+	var listeners []model.Listener
+	var err error
+	if s.config.Pattern != "" {
+		listeners, err = s.config.Scanner.FindByPattern(s.config.Pattern)
+	} else {
+		listeners, err = s.config.Scanner.ListListeners()
+	}
+	s.scanErr = err
 	if err != nil {
 		return err
 	}
@@ -367,32 +224,46 @@ func (s *WatchState) update() error {
 		}
 		listeners = filtered
 	}
+	listeners = s.config.Filter.Filter(listeners)
 
 	// Sort
 	output.SortListeners(listeners, s.config.SortBy)
 
 	// Compute diff
-	current := make(map[int]model.Listener)
-	for _, l := range listeners {
-		current[l.Port] = l
+	current := listenerMap(listeners)
+
+	// Find added listeners
+	s.added = make(map[listenerKey]bool)
+	for key := range current {
+		if _, exists := s.previous[key]; !exists {
+			s.added[key] = true
+		}
 	}
 
-	// Find added ports
-	s.added = make(map[int]bool)
-	for port := range current {
-		if _, exists := s.previous[port]; !exists {
-			s.added[port] = true
+	// Find removed listeners
+	s.removed = make(map[listenerKey]bool)
+	for key := range s.previous {
+		if _, exists := current[key]; !exists {
+			s.removed[key] = true
 		}
 	}
 
-	// Find removed ports
-	s.removed = make(map[int]bool)
-	for port := range s.previous {
-		if _, exists := current[port]; !exists {
-			s.removed[port] = true
+	// Find owner changes and restarts, including ports that came back
+	now := time.Now()
+	s.restarted = make(map[listenerKey]restart)
+	for key, l := range current {
+		if kind, prev := s.restarts.observe(l, now); kind != "" {
+			s.restarted[key] = restart{kind: kind, previous: prev}
 		}
 	}
 
+	// Log changes, skipping the initial scan so existing ports aren't
+	// reported as new
+	if s.scanned {
+		s.log.record(diffListeners(s.previous, current, now))
+	}
+	s.scanned = true
+
 	s.previous = current
 	return nil
 }
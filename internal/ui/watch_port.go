@@ -0,0 +1,295 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tasnimzotder/portman/internal/model"
+	"github.com/tasnimzotder/portman/internal/output"
+)
+
+// PortWatchState tracks the current state of single-port watch mode
+type PortWatchState struct {
+	config        WatchPortConfig
+	listener      *model.Listener // Result of the last successful scan
+	prevSnapshot  *PortSnapshot   // The scan before it, for change highlighting
+	change        restart         // Owner change or restart seen by the last scan
+	appeared      bool            // The port became active in the last scan
+	exited        bool            // The process exited in the last scan
+	scanned       bool
+	scanErr       error // Error of the last scan, shown until one succeeds
+	restarts      *restartTracker
+	history       *portHistory
+	nested        bool // Opened from the all-ports view; 'q' goes back
+	isFirstRender bool
+}
+
+func newPortWatchState(cfg WatchPortConfig, nested bool) *PortWatchState {
+	return &PortWatchState{
+		config:        cfg,
+		restarts:      newRestartTracker(),
+		history:       newPortHistory(cfg.History),
+		nested:        nested,
+		isFirstRender: true,
+	}
+}
+
+// RunWatchPort starts watch mode for a single port
+func RunWatchPort(cfg WatchPortConfig) error {
+	sess := startSession()
+	defer sess.close()
+
+	newPortWatchState(cfg, false).run(sess)
+	return nil
+}
+
+// run refreshes the port view until the user leaves it. It reports whether
+// the whole session should end (interrupt), as opposed to going back.
+func (s *PortWatchState) run(sess *session) bool {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	s.update()
+	s.render()
+
+	for {
+		select {
+		case <-sess.signals:
+			return true
+		case <-sess.resize:
+			s.render()
+		case key := <-sess.keys:
+			switch key {
+			case 'q', 'Q':
+				return !s.nested
+			case KeyEscape, KeyBackspace, KeyLeft:
+				if s.nested {
+					return false
+				}
+			}
+		case <-ticker.C:
+			s.update()
+			s.render()
+		}
+	}
+}
+
+// update scans the port and records the result for render. A failed scan
+// keeps the last result.
+func (s *PortWatchState) update() {
+	listener, err := s.config.Scanner.GetPort(s.config.Port)
+	s.scanErr = err
+	if err != nil {
+		return
+	}
+
+	prev := s.listener
+	s.prevSnapshot = snapshotOf(prev)
+	s.listener = listener
+	s.scanned = true
+	s.appeared = listener != nil && prev == nil
+	s.exited = listener == nil && prev != nil
+	s.change = restart{}
+	if listener == nil {
+		return
+	}
+
+	// Record history for the sparklines, and any restart
+	now := time.Now()
+	s.history.record(*listener, now)
+	s.change.kind, s.change.previous = s.restarts.observe(*listener, now)
+}
+
+// snapshotOf returns the values of a listener that are highlighted when
+// they change, or nil for no listener
+func snapshotOf(l *model.Listener) *PortSnapshot {
+	if l == nil {
+		return nil
+	}
+	snap := &PortSnapshot{PID: l.PID, ConnectionCount: l.ConnectionCount}
+	if l.Stats != nil {
+		snap.MemoryRSS = l.Stats.MemoryRSS
+		snap.CPUPercent = l.Stats.CPUPercent
+		snap.FDCount = l.Stats.FDCount
+		snap.ThreadCount = l.Stats.ThreadCount
+	}
+	return snap
+}
+
+// render draws the detail view of the last scan, sized to the terminal
+func (s *PortWatchState) render() {
+	f := newFrame()
+
+	// Header
+	hint := "Press 'q' to quit"
+	if s.nested {
+		hint = "Press 'q' to go back"
+	}
+	window := "session"
+	if s.config.History > 0 {
+		window = s.config.History.String()
+	}
+	f.add("%s%sportman port %d%s  %sRefresh: %s  History: %s  %s%s", Bold, Cyan, s.config.Port, Reset, Dim, s.config.Interval, window, hint, Reset)
+	f.rule()
+
+	listener := s.listener
+	if !s.scanned {
+		s.addNotice(f, "")
+		s.draw(f)
+		return
+	}
+	if listener == nil {
+		s.addNotice(f, "")
+		f.add("%sPort %d is not in use.%s", Dim, s.config.Port, Reset)
+		if s.exited {
+			f.add("%s  ● Process exited%s", Red, Reset)
+		}
+		s.draw(f)
+		return
+	}
+
+	// Show if newly appeared, restarted, or flapping
+	kind := s.change.kind
+	if kind != "" {
+		s.addNotice(f, fmt.Sprintf("%s  ● Port %d %s%s", Yellow, s.config.Port, describeRestart(s.change, *listener), Reset))
+	} else if s.appeared {
+		s.addNotice(f, fmt.Sprintf("%s  ● Port became active%s", Green, Reset))
+	} else {
+		s.addNotice(f, "")
+	}
+	if n, span, ok := s.restarts.flapping(s.config.Port, time.Now()); ok {
+		f.add("%s%s  ⚠ %s%s", Bold, Yellow, formatFlapping(n, span), Reset)
+	} else {
+		f.blank()
+	}
+
+	// Process info
+	f.add("%sProcess%s", Bold, Reset)
+	if kind != "" {
+		f.add("  PID:         %s%d%s", Yellow, listener.PID, Reset)
+	} else {
+		f.add("  PID:         %d", listener.PID)
+	}
+	if listener.Process != nil {
+		f.add("  Command:     %s", listener.Process.Name)
+		f.add("  User:        %s", listener.Process.User)
+		if listener.Process.UptimeSeconds > 0 {
+			f.add("  Uptime:      %s", time.Duration(listener.Process.UptimeSeconds)*time.Second)
+		}
+	}
+	if n := s.restarts.count(s.config.Port); n > 0 {
+		f.add("  Restarts:    %d this session", n)
+	}
+
+	f.blank()
+	f.add("%sListening%s", Bold, Reset)
+	f.add("  Address:     %s:%d", listener.Address, listener.Port)
+	f.add("  Protocol:    %s", strings.ToUpper(listener.Protocol))
+	if listener.Service != nil {
+		f.add("  Service:     %s", listener.Service.Label())
+	}
+
+	// Connections with change highlighting
+	connChanged := s.prevSnapshot != nil && listener.ConnectionCount != s.prevSnapshot.ConnectionCount
+	f.blank()
+	var connHeading string
+	if connChanged {
+		delta := listener.ConnectionCount - s.prevSnapshot.ConnectionCount
+		sign := "+"
+		if delta < 0 {
+			sign = ""
+		}
+		connHeading = fmt.Sprintf("%sConnections%s %s%d%s (%s%d)%s", Bold, Reset, Yellow, listener.ConnectionCount, Reset, sign, delta, Reset)
+	} else {
+		connHeading = fmt.Sprintf("%sConnections%s (%d)", Bold, Reset, listener.ConnectionCount)
+	}
+	f.add("%s%s", connHeading, sparkSuffix(&s.history.connections, visibleWidth(connHeading), f.width, formatCount))
+	connLine := len(f.lines)
+
+	// Stats with change highlighting, built first so connections get the
+	// remaining height
+	stats := &frame{width: f.width, height: f.height}
+	stats.blank()
+	stats.add("%sStats%s", Bold, Reset)
+	if listener.Stats != nil {
+		memChanged := s.prevSnapshot != nil && listener.Stats.MemoryRSS != s.prevSnapshot.MemoryRSS
+		cpuChanged := s.prevSnapshot != nil && listener.Stats.CPUPercent != s.prevSnapshot.CPUPercent
+		fdChanged := s.prevSnapshot != nil && listener.Stats.FDCount != s.prevSnapshot.FDCount
+		threadChanged := s.prevSnapshot != nil && listener.Stats.ThreadCount != s.prevSnapshot.ThreadCount
+
+		s.addMetric(stats, "Memory:", highlight(output.FormatBytes(listener.Stats.MemoryRSS), memChanged), &s.history.memory, formatMemory)
+		s.addMetric(stats, "CPU:", highlight(formatPercent(listener.Stats.CPUPercent), cpuChanged), &s.history.cpu, formatPercent)
+		s.addMetric(stats, "FDs:", highlight(fmt.Sprintf("%d", listener.Stats.FDCount), fdChanged), &s.history.fds, formatCount)
+		s.addMetric(stats, "Threads:", highlight(fmt.Sprintf("%d", listener.Stats.ThreadCount), threadChanged), &s.history.threads, formatCount)
+	} else {
+		stats.add("  %sNo stats available%s", Dim, Reset)
+	}
+
+	// Show as many connections as fit, keeping a line for the overflow note
+	if len(listener.Connections) > 0 {
+		room := max(1, f.height-connLine-len(stats.lines))
+		shown := len(listener.Connections)
+		if shown > room {
+			shown = max(1, room-1)
+		}
+		for _, c := range listener.Connections[:shown] {
+			f.add("  %s:%d  %s", c.RemoteAddr, c.RemotePort, c.State)
+		}
+		if shown < len(listener.Connections) {
+			f.add("  %s... and %d more%s", Dim, len(listener.Connections)-shown, Reset)
+		}
+	}
+
+	f.lines = append(f.lines, stats.lines...)
+	s.draw(f)
+}
+
+// addNotice adds the line under the header: the error of the last scan if
+// it failed, or else line
+func (s *PortWatchState) addNotice(f *frame, line string) {
+	if s.scanErr != nil {
+		msg, _, _ := strings.Cut(s.scanErr.Error(), "\n")
+		line = fmt.Sprintf("%s  ✗ Scan failed: %s%s", Red, msg, Reset)
+	}
+	f.add("%s", line)
+}
+
+// draw writes the frame, clearing the screen on the first render
+func (s *PortWatchState) draw(f *frame) {
+	f.draw(s.isFirstRender)
+	s.isFirstRender = false
+}
+
+// addMetric adds a stats line with its value and sparkline history
+func (s *PortWatchState) addMetric(f *frame, label, value string, ser *series, format func(float64) string) {
+	line := fmt.Sprintf("  %-12s %s", label, value)
+	if pad := 26 - visibleWidth(line); pad > 0 {
+		line += strings.Repeat(" ", pad)
+	}
+	f.add("%s%s", line, sparkSuffix(ser, visibleWidth(line), f.width, format))
+}
+
+func formatCount(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+func formatPercent(v float64) string {
+	return fmt.Sprintf("%.1f%%", v)
+}
+
+func formatMemory(v float64) string {
+	return output.FormatBytes(int64(v))
+}
+
+// highlight colors a value yellow when it changed since the last refresh
+func highlight(value string, changed bool) string {
+	if changed {
+		return Yellow + value + Reset
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+func TestWatchPortShowsScanErrors(t *testing.T) {
+	boom := errors.New("lsof: permission denied")
+	up := []model.Listener{listener(8080, "tcp", "127.0.0.1", 20, "node", 0)}
+	s := newPortWatchState(WatchPortConfig{Port: 8080, Scanner: &fakeScanner{scans: []fakeScan{
+		{err: boom},
+		{listeners: up},
+		{err: boom},
+		{},
+	}}}, false)
+
+	tests := []struct {
+		shown []string
+		gone  []string
+	}{
+		{[]string{"Scan failed: lsof: permission denied"}, []string{"not in use"}},
+		{[]string{"Port became active", "PID:         20"}, []string{"Scan failed"}},
+		{[]string{"Scan failed", "PID:         20"}, []string{"not in use"}}, // The last result stays
+		{[]string{"Port 8080 is not in use", "Process exited"}, []string{"Scan failed"}},
+	}
+	for i, tt := range tests {
+		s.update()
+		out := captureStdout(t, s.render)
+		for _, want := range tt.shown {
+			if !strings.Contains(out, want) {
+				t.Errorf("scan %d: %q not shown", i, want)
+			}
+		}
+		for _, unwanted := range tt.gone {
+			if strings.Contains(out, unwanted) {
+				t.Errorf("scan %d: %q shown", i, unwanted)
+			}
+		}
+	}
+}
+
+func TestWatchPortRedrawDoesNotRescan(t *testing.T) {
+	scanner := &fakeScanner{scans: []fakeScan{
+		{listeners: []model.Listener{listener(8080, "tcp", "127.0.0.1", 20, "node", 3)}},
+		{listeners: []model.Listener{listener(8080, "tcp", "127.0.0.1", 21, "node", 3)}},
+	}}
+	s := newPortWatchState(WatchPortConfig{Port: 8080, Scanner: scanner}, false)
+	s.update()
+	s.update()
+
+	// A resize redraws the last result
+	first := captureStdout(t, s.render)
+	second := captureStdout(t, s.render)
+	if scanner.calls != 2 || len(s.history.connections.samples) != 2 || s.restarts.count(8080) != 1 {
+		t.Errorf("render() scanned: %d scans, %d samples, %d restarts; want 2, 2, 1",
+			scanner.calls, len(s.history.connections.samples), s.restarts.count(8080))
+	}
+	if !strings.Contains(second, "restarted") || strings.TrimPrefix(first, ClearScreen) != second {
+		t.Errorf("a redraw should show the same view:\n%q\n%q", first, second)
+	}
+}
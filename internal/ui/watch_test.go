@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tasnimzotder/portman/internal/model"
+)
+
+// fakeScanner returns its scans in turn, repeating the last one
+type fakeScanner struct {
+	scans []fakeScan
+	calls int
+}
+
+type fakeScan struct {
+	listeners []model.Listener
+	err       error
+}
+
+func (s *fakeScanner) next() fakeScan {
+	scan := s.scans[min(s.calls, len(s.scans)-1)]
+	s.calls++
+	return scan
+}
+
+func (s *fakeScanner) ListListeners() ([]model.Listener, error) {
+	scan := s.next()
+	return scan.listeners, scan.err
+}
+
+func (s *fakeScanner) GetPort(port int) (*model.Listener, error) {
+	scan := s.next()
+	for _, l := range scan.listeners {
+		if l.Port == port {
+			return &l, scan.err
+		}
+	}
+	return nil, scan.err
+}
+
+func (s *fakeScanner) FindByPattern(pattern string) ([]model.Listener, error) {
+	return s.ListListeners()
+}
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestWatchShowsScanErrors(t *testing.T) {
+	boom := errors.New("lsof: permission denied\nmore detail")
+	up := []model.Listener{listener(8080, "tcp", "127.0.0.1", 20, "node", 0)}
+	s := newWatchState(WatchConfig{Scanner: &fakeScanner{scans: []fakeScan{
+		{listeners: up},
+		{err: boom},
+		{listeners: up},
+	}}})
+
+	for i, want := range []bool{false, true, false} {
+		err := s.update()
+		out := captureStdout(t, s.render)
+		if shown := strings.Contains(out, "Scan failed: lsof: permission denied"); shown != want || (err != nil) != want {
+			t.Errorf("scan %d: update() = %v, error shown = %v; want shown = %v", i, err, shown, want)
+		}
+		if !strings.Contains(out, "8080") {
+			t.Errorf("scan %d: the last listeners should stay on screen", i)
+		}
+		if strings.Contains(out, "more detail") {
+			t.Errorf("scan %d: only the first line of the error belongs in the status line", i)
+		}
+	}
+}